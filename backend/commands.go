@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"life-rpg/database"
+	"life-rpg/services"
+)
+
+// runCommand 执行命令行子命令，返回是否匹配到子命令
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "reconcile":
+		runReconcile(args[1:])
+		return true
+	}
+	return false
+}
+
+// runReconcile 对账子命令
+// 用法: life-rpg reconcile [-user 用户ID] [-fix]
+func runReconcile(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	userID := fs.Uint("user", 0, "只检查指定用户, 0表示全部用户")
+	fix := fs.Bool("fix", false, "为偏差写入修正流水")
+	fs.Parse(args)
+
+	database.InitDB()
+
+	var reports []*services.ReconcileReport
+	if *userID != 0 {
+		report, err := services.ReconcileUser(database.DB, uint(*userID), *fix)
+		if err != nil {
+			log.Fatalf("对账失败: %v", err)
+		}
+		if !report.Clean() {
+			reports = append(reports, report)
+		}
+	} else {
+		var err error
+		if reports, err = services.ReconcileAll(database.DB, *fix); err != nil {
+			log.Fatalf("对账失败: %v", err)
+		}
+	}
+
+	if len(reports) == 0 {
+		log.Println("对账完成, 余额与流水一致")
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(reports)
+	fmt.Fprintf(os.Stderr, "对账完成, %d 个用户存在问题\n", len(reports))
+	if !*fix {
+		os.Exit(1)
+	}
+}
@@ -3,13 +3,22 @@ package config
 
 import (
 	"os"
+	"strconv"
 )
 
 // Config 应用配置
 type Config struct {
-	DB     DBConfig
-	JWT    JWTConfig
-	Server ServerConfig
+	DB        DBConfig
+	JWT       JWTConfig
+	Server    ServerConfig
+	Transfer  TransferConfig
+	Checkin   CheckinConfig
+	Guild     GuildConfig
+	Household HouseholdConfig
+	Feed      FeedConfig
+	Reminder  ReminderConfig
+	Mail      MailConfig
+	Webhook   WebhookConfig
 }
 
 // DBConfig 数据库配置
@@ -32,6 +41,66 @@ type ServerConfig struct {
 	Port string
 }
 
+// TransferConfig 转账赠礼风控配置, 金额均按金币计
+type TransferConfig struct {
+	DailyLimit        int // 每日转出金额上限
+	DailyReceiveLimit int // 每日转入金额上限
+	DailyCount        int // 每日转出次数上限
+	MaxPerTransfer    int // 单笔上限
+	MinLevel          int // 转出方最低等级
+	MinAccountDays    int // 转出方最短注册天数
+}
+
+// FeedConfig 动态配置
+type FeedConfig struct {
+	BigPurchase int // 兑换价格达到该值时发布动态
+}
+
+// ReminderConfig 任务提醒配置
+type ReminderConfig struct {
+	DefaultTimezone string // 未指定时区时使用的时区
+	MaxPerUser      int    // 每个用户最多设置的提醒数
+}
+
+// MailConfig 邮件配置, Host 为空时不启用邮件
+type MailConfig struct {
+	Host        string
+	Port        string
+	Username    string // 为空时不进行SMTP认证, 便于连接本地测试服务器
+	Password    string
+	From        string // 发件人, 如 Life RPG <noreply@example.com>
+	MaxAttempts int    // 发送失败的最大尝试次数
+	ResetURL    string // 重置密码页面地址, 邮件中附加 ?token=
+	VerifyURL   string // 验证邮箱页面地址, 邮件中附加 ?token=
+}
+
+// WebhookConfig Webhook配置
+type WebhookConfig struct {
+	MaxAttempts  int  // 投递失败的最大尝试次数
+	Timeout      int  // 单次投递超时(秒)
+	MaxPerOwner  int  // 每个用户 (或空间管理员) 最多配置的订阅数
+	AllowPrivate bool // 是否允许空间级订阅投递到内网地址, 对接局域网内的智能家居时开启; 用户订阅始终禁止
+}
+
+// GuildConfig 公会周目标配置, 防止以极低目标反复领取高额奖励
+type GuildConfig struct {
+	MaxGoalGold        int // 周目标奖励金币上限
+	MaxGoalExp         int // 周目标奖励经验上限
+	MinTargetPerMember int // 设置奖励时周目标至少为 成员数 * 该值
+}
+
+// HouseholdConfig 家庭配置
+type HouseholdConfig struct {
+	MaxTaskGold int // 家庭任务金币奖励上限
+	MaxTaskExp  int // 家庭任务经验奖励上限
+}
+
+// CheckinConfig 签到配置
+type CheckinConfig struct {
+	MakeupCost  int // 补签消耗金币
+	MakeupLimit int // 每月补签次数上限
+}
+
 // AppConfig 全局配置实例
 var AppConfig *Config
 
@@ -52,6 +121,50 @@ func InitConfig() {
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
+		Transfer: TransferConfig{
+			DailyLimit:        getEnvInt("TRANSFER_DAILY_LIMIT", 500),
+			DailyReceiveLimit: getEnvInt("TRANSFER_DAILY_RECEIVE_LIMIT", 1000),
+			DailyCount:        getEnvInt("TRANSFER_DAILY_COUNT", 10),
+			MaxPerTransfer:    getEnvInt("TRANSFER_MAX_PER_TRANSFER", 200),
+			MinLevel:          getEnvInt("TRANSFER_MIN_LEVEL", 2),
+			MinAccountDays:    getEnvInt("TRANSFER_MIN_ACCOUNT_DAYS", 3),
+		},
+		Checkin: CheckinConfig{
+			MakeupCost:  getEnvInt("CHECKIN_MAKEUP_COST", 50),
+			MakeupLimit: getEnvInt("CHECKIN_MAKEUP_LIMIT", 3),
+		},
+		Guild: GuildConfig{
+			MaxGoalGold:        getEnvInt("GUILD_MAX_GOAL_GOLD", 100),
+			MaxGoalExp:         getEnvInt("GUILD_MAX_GOAL_EXP", 200),
+			MinTargetPerMember: getEnvInt("GUILD_MIN_TARGET_PER_MEMBER", 5),
+		},
+		Household: HouseholdConfig{
+			MaxTaskGold: getEnvInt("HOUSEHOLD_MAX_TASK_GOLD", 100),
+			MaxTaskExp:  getEnvInt("HOUSEHOLD_MAX_TASK_EXP", 200),
+		},
+		Feed: FeedConfig{
+			BigPurchase: getEnvInt("FEED_BIG_PURCHASE", 500),
+		},
+		Reminder: ReminderConfig{
+			DefaultTimezone: getEnv("REMINDER_TIMEZONE", "Asia/Shanghai"),
+			MaxPerUser:      getEnvInt("REMINDER_MAX_PER_USER", 20),
+		},
+		Mail: MailConfig{
+			Host:        getEnv("MAIL_HOST", ""),
+			Port:        getEnv("MAIL_PORT", "25"),
+			Username:    getEnv("MAIL_USERNAME", ""),
+			Password:    getEnv("MAIL_PASSWORD", ""),
+			From:        getEnv("MAIL_FROM", "Life RPG <noreply@localhost>"),
+			MaxAttempts: getEnvInt("MAIL_MAX_ATTEMPTS", 5),
+			ResetURL:    getEnv("MAIL_RESET_URL", "http://localhost:5173/reset-password"),
+			VerifyURL:   getEnv("MAIL_VERIFY_URL", "http://localhost:5173/verify-email"),
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			Timeout:      getEnvInt("WEBHOOK_TIMEOUT", 10),
+			MaxPerOwner:  getEnvInt("WEBHOOK_MAX_PER_OWNER", 10),
+			AllowPrivate: getEnv("WEBHOOK_ALLOW_PRIVATE", "false") == "true",
+		},
 	}
 }
 
@@ -62,3 +175,13 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt 获取整数环境变量，不存在或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,160 @@
+// Package controllers 监督伙伴控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountabilityController 监督伙伴控制器
+type AccountabilityController struct{}
+
+// NominateRequest 指定监督伙伴请求
+type NominateRequest struct {
+	PartnerID uint   `json:"partnerId" binding:"required"`
+	TaskIDs   []uint `json:"taskIds" binding:"required"`
+}
+
+// NudgeRequest 提醒请求
+type NudgeRequest struct {
+	Message string `json:"message"`
+}
+
+// Overview 我的监督伙伴, 以及我正在监督的好友和其今日完成情况
+func (ac *AccountabilityController) Overview(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var mine *models.Accountability
+	var link models.Accountability
+	if err := database.Tenant(c).Preload("Partner", userBriefPreload).Preload("Tasks.Task", services.Unscoped).
+		Where("user_id = ?", userID).First(&link).Error; err == nil {
+		mine = &link
+	}
+
+	var watching []models.Accountability
+	database.Tenant(c).Preload("User", userBriefPreload).Preload("Tasks.Task", services.Unscoped).
+		Where("partner_id = ?", userID).
+		Order("id desc").
+		Find(&watching)
+
+	list := make([]gin.H, 0, len(watching))
+	for i := range watching {
+		item := gin.H{"accountability": watching[i]}
+		if watching[i].Status == services.FriendAccepted {
+			item["doneToday"] = services.WatchedTasksToday(database.Tenant(c), &watching[i])
+		}
+		list = append(list, item)
+	}
+
+	utils.Success(c, gin.H{
+		"mine":     mine,
+		"watching": list,
+	})
+}
+
+// Nominate 指定监督伙伴
+func (ac *AccountabilityController) Nominate(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req NominateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	link, err := services.NominatePartner(tx, userID, req.PartnerID, req.TaskIDs)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, accountabilityError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "邀请已发送", link)
+}
+
+// Remove 解除我的监督伙伴
+func (ac *AccountabilityController) Remove(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.RemovePartner(tx, userID); err != nil {
+		tx.Rollback()
+		utils.Fail(c, accountabilityError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已解除", nil)
+}
+
+// Accept 接受监督邀请
+func (ac *AccountabilityController) Accept(c *gin.Context) {
+	ac.respond(c, true)
+}
+
+// Decline 拒绝监督邀请或退出监督
+func (ac *AccountabilityController) Decline(c *gin.Context) {
+	ac.respond(c, false)
+}
+
+// respond 处理监督邀请
+func (ac *AccountabilityController) respond(c *gin.Context, accept bool) {
+	userID := middleware.GetCurrentUserID(c)
+	linkID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	link, err := services.RespondPartner(tx, userID, uint(linkID), accept)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, accountabilityError(err))
+		return
+	}
+	tx.Commit()
+
+	if accept {
+		utils.SuccessWithMessage(c, "已成为监督伙伴", link)
+		return
+	}
+	utils.SuccessWithMessage(c, "已退出监督", nil)
+}
+
+// Nudge 提醒被监督的好友
+func (ac *AccountabilityController) Nudge(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	linkID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req NudgeRequest
+	_ = c.ShouldBindJSON(&req)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.Nudge(tx, userID, uint(linkID), req.Message); err != nil {
+		tx.Rollback()
+		utils.Fail(c, accountabilityError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "提醒已发送", nil)
+}
+
+// accountabilityError 转换监督伙伴操作失败原因
+func accountabilityError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrPartnerSelf), errors.Is(err, services.ErrPartnerFriend),
+		errors.Is(err, services.ErrPartnerTasks), errors.Is(err, services.ErrPartnerNotFound),
+		errors.Is(err, services.ErrNudgeLimit):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"strconv"
 
 	"life-rpg/database"
+	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/realtime"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -22,7 +24,7 @@ func (ac *AnnouncementController) List(c *gin.Context) {
 	var announcements []models.Announcement
 	var total int64
 
-	query := database.DB.Model(&models.Announcement{})
+	query := database.Tenant(c).Model(&models.Announcement{})
 	query.Count(&total)
 	query.Order("sort, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&announcements)
 
@@ -37,11 +39,20 @@ func (ac *AnnouncementController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&announcement).Error; err != nil {
+	if err := database.Tenant(c).Create(&announcement).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
 
+	// 推送给当前空间的在线用户, 启用状态以数据库默认值为准
+	database.Tenant(c).First(&announcement, announcement.ID)
+	if announcement.IsActive {
+		realtime.Publish(realtime.TenantTopic(middleware.GetCurrentTenantID(c)), realtime.Event{
+			Type: realtime.EventAnnouncement,
+			Data: announcement,
+		})
+	}
+
 	utils.SuccessWithMessage(c, "创建成功", announcement)
 }
 
@@ -49,7 +60,7 @@ func (ac *AnnouncementController) Create(c *gin.Context) {
 func (ac *AnnouncementController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var announcement models.Announcement
-	if err := database.DB.First(&announcement, id).Error; err != nil {
+	if err := database.Tenant(c).First(&announcement, id).Error; err != nil {
 		utils.Fail(c, "公告不存在")
 		return
 	}
@@ -60,14 +71,14 @@ func (ac *AnnouncementController) Update(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&announcement).Updates(updateData)
+	database.Tenant(c).Model(&announcement).Updates(updateData)
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
 // Delete 删除公告
 func (ac *AnnouncementController) Delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := database.DB.Delete(&models.Announcement{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.Announcement{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
@@ -79,6 +90,6 @@ func (ac *AnnouncementController) Delete(c *gin.Context) {
 // UserAnnouncementList 用户公告列表 (H5端)
 func (ac *AnnouncementController) UserAnnouncementList(c *gin.Context) {
 	var announcements []models.Announcement
-	database.DB.Where("is_active = ?", true).Order("sort, created_at desc").Limit(10).Find(&announcements)
+	database.Tenant(c).Where("is_active = ?", true).Order("sort, created_at desc").Limit(10).Find(&announcements)
 	utils.Success(c, announcements)
 }
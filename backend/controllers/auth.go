@@ -2,9 +2,12 @@
 package controllers
 
 import (
+	"errors"
+
 	"life-rpg/database"
 	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/services"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -60,12 +63,19 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	// 检查所属空间
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, user.TenantID).Error; err != nil || !tenant.IsActive {
+		utils.Fail(c, "所属空间已停用")
+		return
+	}
+
 	// 生成Token
 	roleKey := ""
 	if user.Role != nil {
 		roleKey = user.Role.Key
 	}
-	token, err := middleware.GenerateToken(user.ID, user.Username, user.RoleID, roleKey)
+	token, err := middleware.GenerateToken(user.ID, user.Username, user.RoleID, roleKey, user.TenantID)
 	if err != nil {
 		utils.Fail(c, "Token生成失败")
 		return
@@ -82,6 +92,8 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=20"`
 	Password string `json:"password" binding:"required,min=6"`
 	Nickname string `json:"nickname"`
+	Email    string `json:"email"`  // 选填, 用于找回密码和邮件通知
+	Tenant   string `json:"tenant"` // 空间标识, 为空时注册到默认空间
 }
 
 // Register 用户注册
@@ -107,19 +119,46 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
+	if req.Email != "" {
+		email, err := services.NormalizeEmail(req.Email)
+		if err != nil {
+			utils.Fail(c, err.Error())
+			return
+		}
+		req.Email = email
+	}
+
+	// 查找注册的空间
+	var tenant models.Tenant
+	query := database.DB.Where("is_active = ?", true)
+	if req.Tenant != "" {
+		query = query.Where("code = ?", req.Tenant)
+	} else {
+		query = query.Where("id = ?", database.DefaultTenantID)
+	}
+	if err := query.First(&tenant).Error; err != nil {
+		utils.Fail(c, "空间不存在或已停用")
+		return
+	}
+
 	// 加密密码
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 
 	// 获取普通用户角色
 	var userRole models.SysRole
-	database.DB.Where("key = ?", "user").First(&userRole)
+	if err := database.DB.Where(&models.SysRole{Key: "user"}).First(&userRole).Error; err != nil {
+		utils.Fail(c, "用户角色不存在")
+		return
+	}
 
 	// 创建用户
 	user := models.SysUser{
 		Username: req.Username,
 		Password: string(hashedPassword),
 		Nickname: req.Nickname,
+		Email:    req.Email,
 		RoleID:   userRole.ID,
+		TenantID: tenant.ID,
 		Gold:     0,
 		Exp:      0,
 		Level:    1,
@@ -134,6 +173,128 @@ func (ac *AuthController) Register(c *gin.Context) {
 	utils.SuccessWithMessage(c, "注册成功", nil)
 }
 
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// ForgotPassword 找回密码, 向账号绑定的邮箱发送重置链接
+// @Summary 找回密码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body ForgotPasswordRequest true "用户名"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/forgot-password [post]
+func (ac *AuthController) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if !services.MailEnabled() {
+		utils.Fail(c, services.ErrMailDisabled.Error())
+		return
+	}
+
+	// 账号不存在或未绑定邮箱时同样返回成功, 避免被用来探测账号
+	const message = "如果该账号绑定了邮箱, 重置链接已发送"
+	var user models.SysUser
+	if err := database.DB.Where("username = ? AND status = 1", req.Username).First(&user).Error; err != nil || user.Email == "" {
+		utils.SuccessWithMessage(c, message, nil)
+		return
+	}
+
+	tx := database.DB.Begin()
+	if err := services.RequestPasswordReset(tx, &user); err != nil {
+		tx.Rollback()
+		utils.Fail(c, "发送失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, message, nil)
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ResetPassword 通过邮件中的令牌重置密码
+// @Summary 重置密码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body ResetPasswordRequest true "令牌和新密码"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/reset-password [post]
+func (ac *AuthController) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+
+	tx := database.DB.Begin()
+	if err := services.ResetPassword(tx, req.Token, string(hashedPassword)); err != nil {
+		tx.Rollback()
+		if errors.Is(err, services.ErrResetToken) {
+			utils.Fail(c, err.Error())
+		} else {
+			utils.Fail(c, "重置失败")
+		}
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "密码已重置, 请重新登录", nil)
+}
+
+// VerifyEmailRequest 验证邮箱请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmail 通过邮件中的令牌确认新邮箱
+// @Summary 验证邮箱
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body VerifyEmailRequest true "令牌"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/verify-email [post]
+func (ac *AuthController) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.DB.Begin()
+	if err := services.VerifyEmail(tx, req.Token); err != nil {
+		tx.Rollback()
+		if errors.Is(err, services.ErrVerifyToken) {
+			utils.Fail(c, err.Error())
+		} else {
+			utils.Fail(c, "验证失败")
+		}
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "邮箱已验证", nil)
+}
+
+// UserInfoResponse 当前用户信息, 附带未读通知数
+type UserInfoResponse struct {
+	*models.SysUser
+	UnreadCount int64 `json:"unreadCount"`
+}
+
 // GetUserInfo 获取当前用户信息
 // @Summary 获取当前用户信息
 // @Tags 认证
@@ -150,7 +311,10 @@ func (ac *AuthController) GetUserInfo(c *gin.Context) {
 		return
 	}
 
-	utils.Success(c, &user)
+	utils.Success(c, UserInfoResponse{
+		SysUser:     &user,
+		UnreadCount: services.UnreadCount(database.DB, userID),
+	})
 }
 
 // GetUserMenus 获取当前用户菜单
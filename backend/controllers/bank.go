@@ -0,0 +1,191 @@
+// Package controllers 金币银行控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BankController 银行控制器
+type BankController struct{}
+
+// ProductList 存款产品列表 (管理端)
+func (bc *BankController) ProductList(c *gin.Context) {
+	var products []models.BankProduct
+	database.Tenant(c).Order("sort, id").Find(&products)
+	utils.Success(c, products)
+}
+
+// CreateProduct 创建存款产品
+func (bc *BankController) CreateProduct(c *gin.Context) {
+	var product models.BankProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if product.TermDays <= 0 || product.Rate < 0 || product.PenaltyRate < 0 || product.PenaltyRate > 100 {
+		utils.Fail(c, "存期、利率或罚金比例不合法")
+		return
+	}
+
+	if err := database.Tenant(c).Create(&product).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", product)
+}
+
+// UpdateProduct 更新存款产品 (已存入的存款按存入时的条件结算)
+func (bc *BankController) UpdateProduct(c *gin.Context) {
+	id := c.Param("id")
+	var product models.BankProduct
+	if err := database.Tenant(c).First(&product, id).Error; err != nil {
+		utils.Fail(c, "产品不存在")
+		return
+	}
+
+	var updateData models.BankProduct
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if updateData.TermDays < 0 || updateData.Rate < 0 || updateData.PenaltyRate < 0 || updateData.PenaltyRate > 100 {
+		utils.Fail(c, "存期、利率或罚金比例不合法")
+		return
+	}
+
+	database.Tenant(c).Model(&product).Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// DeleteProduct 删除存款产品
+func (bc *BankController) DeleteProduct(c *gin.Context) {
+	id := c.Param("id")
+	if err := database.Tenant(c).Delete(&models.BankProduct{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// DepositList 存款记录 (管理端)
+func (bc *BankController) DepositList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	status := c.Query("status")
+	userID := c.Query("userId")
+
+	var deposits []models.BankDeposit
+	var total int64
+
+	query := database.Tenant(c).Model(&models.BankDeposit{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	query.Count(&total)
+	query.Preload("Product", services.Unscoped).Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deposits)
+
+	utils.PageSuccess(c, deposits, total, page, pageSize)
+}
+
+// ===== 用户端接口 =====
+
+// OpenDepositRequest 存款请求
+type OpenDepositRequest struct {
+	ProductID uint `json:"productId" binding:"required"`
+	Amount    int  `json:"amount" binding:"required,gt=0"`
+}
+
+// UserProductList 可存款产品 (H5端)
+func (bc *BankController) UserProductList(c *gin.Context) {
+	var products []models.BankProduct
+	database.Tenant(c).Where("is_active = ?", true).Order("sort, id").Find(&products)
+	utils.Success(c, products)
+}
+
+// UserDepositList 我的存款 (H5端)
+func (bc *BankController) UserDepositList(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	status := c.Query("status")
+
+	query := database.Tenant(c).Preload("Product", services.Unscoped).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deposits []models.BankDeposit
+	query.Order("id desc").Find(&deposits)
+	utils.Success(c, deposits)
+}
+
+// OpenDeposit 存入定期
+func (bc *BankController) OpenDeposit(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req OpenDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var product models.BankProduct
+	if err := database.Tenant(c).Where("is_active = ?", true).First(&product, req.ProductID).Error; err != nil {
+		utils.Fail(c, "产品不存在")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	deposit, err := services.OpenDeposit(tx, userID, &product, req.Amount)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, bankError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "存入成功", deposit)
+}
+
+// WithdrawDeposit 支取存款
+func (bc *BankController) WithdrawDeposit(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	depositID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	deposit, err := services.WithdrawDeposit(tx, userID, uint(depositID))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, bankError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "支取成功", deposit)
+}
+
+// bankError 转换银行操作失败原因
+func bankError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "存款不存在"
+	case errors.Is(err, services.ErrDepositTooSmall), errors.Is(err, services.ErrDepositOverMax),
+		errors.Is(err, services.ErrDepositClosed), errors.Is(err, services.ErrInsufficientGold):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
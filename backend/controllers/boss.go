@@ -0,0 +1,221 @@
+// Package controllers 首领讨伐控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BossController 首领控制器
+type BossController struct{}
+
+// List 首领活动列表 (管理端)
+func (bc *BossController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	status := c.Query("status")
+
+	var bosses []models.Boss
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Boss{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&bosses)
+
+	utils.PageSuccess(c, bosses, total, page, pageSize)
+}
+
+// Create 创建首领活动
+func (bc *BossController) Create(c *gin.Context) {
+	var boss models.Boss
+	if err := c.ShouldBindJSON(&boss); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateBoss(&boss); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+	boss.HP = boss.MaxHP
+	boss.Status = services.BossActive
+	boss.DefeatedAt = nil
+	boss.LastCounterDate = ""
+
+	if err := database.Tenant(c).Create(&boss).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", boss)
+}
+
+// Update 更新首领活动 (生命值与状态由战斗结算维护, 不可修改)
+func (bc *BossController) Update(c *gin.Context) {
+	id := c.Param("id")
+	var boss models.Boss
+	if err := database.Tenant(c).First(&boss, id).Error; err != nil {
+		utils.Fail(c, "首领不存在")
+		return
+	}
+	if boss.Status != services.BossActive {
+		utils.Fail(c, "首领活动已结束, 不可修改")
+		return
+	}
+
+	var updateData models.Boss
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateBoss(&updateData); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	database.Tenant(c).Model(&boss).Omit("hp", "max_hp", "status", "defeated_at", "last_counter_date").Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// Delete 删除首领活动
+func (bc *BossController) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if err := database.Tenant(c).Delete(&models.Boss{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// Damages 首领战斗记录
+func (bc *BossController) Damages(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	kind := c.Query("kind")
+
+	var damages []models.BossDamage
+	var total int64
+
+	query := database.Tenant(c).Model(&models.BossDamage{}).Where("boss_id = ?", c.Param("id"))
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	query.Count(&total)
+	query.Preload("User", userBriefPreload).Order("id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&damages)
+
+	utils.PageSuccess(c, damages, total, page, pageSize)
+}
+
+// validateBoss 校验首领参数
+func validateBoss(boss *models.Boss) string {
+	if boss.Name == "" {
+		return "首领名称不能为空"
+	}
+	if boss.MaxHP <= 0 {
+		return "首领生命值必须大于0"
+	}
+	if !boss.EndAt.After(boss.StartAt) {
+		return "结束时间需晚于开始时间"
+	}
+	if boss.DamagePercent < 0 || boss.CounterHeal < 0 || boss.CounterGold < 0 {
+		return "伤害比例与反击数值不能为负"
+	}
+	return ""
+}
+
+// ===== 用户端接口 =====
+
+// UserList 进行中与近期结束的首领 (H5端)
+func (bc *BossController) UserList(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var bosses []models.Boss
+	database.Tenant(c).Where("start_at <= ? AND end_at > ?", time.Now(), time.Now().AddDate(0, 0, -7)).
+		Order("status, end_at").
+		Find(&bosses)
+
+	var joined []uint
+	database.Tenant(c).Model(&models.BossParticipant{}).Where("user_id = ?", userID).Pluck("boss_id", &joined)
+	joinedSet := make(map[uint]bool, len(joined))
+	for _, id := range joined {
+		joinedSet[id] = true
+	}
+
+	list := make([]gin.H, 0, len(bosses))
+	for _, boss := range bosses {
+		list = append(list, gin.H{
+			"boss":   boss,
+			"joined": joinedSet[boss.ID],
+		})
+	}
+	utils.Success(c, list)
+}
+
+// Detail 首领详情: 伤害排行与最近战斗记录
+func (bc *BossController) Detail(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var boss models.Boss
+	if err := database.Tenant(c).First(&boss, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "首领不存在")
+		return
+	}
+
+	var participants []models.BossParticipant
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("boss_id = ?", boss.ID).
+		Order("damage desc").
+		Find(&participants)
+
+	var damages []models.BossDamage
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("boss_id = ?", boss.ID).
+		Order("id desc").Limit(20).
+		Find(&damages)
+
+	joined := false
+	for _, p := range participants {
+		if p.UserID == userID {
+			joined = true
+		}
+	}
+
+	utils.Success(c, gin.H{
+		"boss":         boss,
+		"joined":       joined,
+		"participants": participants,
+		"damages":      damages,
+	})
+}
+
+// Join 参与首领讨伐
+func (bc *BossController) Join(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	bossID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	participant, err := services.JoinBoss(database.Tenant(c), userID, uint(bossID))
+	if err != nil {
+		if errors.Is(err, services.ErrBossNotActive) || errors.Is(err, services.ErrBossJoined) {
+			utils.Fail(c, err.Error())
+			return
+		}
+		utils.Fail(c, "参与失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "参与成功", participant)
+}
@@ -0,0 +1,237 @@
+// Package controllers 好友挑战控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ChallengeController 挑战控制器
+type ChallengeController struct{}
+
+// ChallengeRequest 发起挑战请求
+type ChallengeRequest struct {
+	TaskID    uint   `json:"taskId" binding:"required"`
+	Target    int    `json:"target" binding:"required"`
+	Days      int    `json:"days"`
+	Stake     int    `json:"stake"`
+	FriendIDs []uint `json:"friendIds" binding:"required"`
+}
+
+// AdminList 挑战列表 (管理端)
+func (cc *ChallengeController) AdminList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	status := c.Query("status")
+
+	var challenges []models.Challenge
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Challenge{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	query.Preload("Creator", userBriefPreload).Preload("Task", services.Unscoped).
+		Preload("Participants.User", userBriefPreload).
+		Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&challenges)
+
+	utils.PageSuccess(c, challenges, total, page, pageSize)
+}
+
+// ===== 用户端接口 =====
+
+// List 我发起或收到的挑战 (H5端)
+func (cc *ChallengeController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	status := c.Query("status")
+
+	var challenges []models.Challenge
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Challenge{}).
+		Where("id IN (?)", database.Tenant(c).Model(&models.ChallengeParticipant{}).
+			Select("challenge_id").Where("user_id = ?", userID))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	query.Preload("Creator", userBriefPreload).Preload("Task", services.Unscoped).
+		Preload("Participants.User", userBriefPreload).
+		Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&challenges)
+
+	utils.PageSuccess(c, challenges, total, page, pageSize)
+}
+
+// Create 发起挑战
+func (cc *ChallengeController) Create(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req ChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 7
+	}
+
+	tx := database.Tenant(c).Begin()
+	challenge, err := services.CreateChallenge(tx, userID, req.TaskID, req.Target, req.Days, req.Stake, req.FriendIDs)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, challengeError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "挑战已发起", challenge)
+}
+
+// Detail 挑战详情, 进行中的挑战返回实时完成次数
+func (cc *ChallengeController) Detail(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var challenge models.Challenge
+	if err := database.Tenant(c).Preload("Creator", userBriefPreload).Preload("Task", services.Unscoped).
+		Preload("Participants.User", userBriefPreload).
+		First(&challenge, c.Param("id")).Error; err != nil || !inChallenge(&challenge, userID) {
+		utils.Fail(c, "挑战不存在")
+		return
+	}
+
+	if challenge.Status == services.ChallengeActive {
+		for i := range challenge.Participants {
+			p := &challenge.Participants[i]
+			if p.Status == services.ParticipantAccepted {
+				p.Progress = services.ChallengeProgress(database.Tenant(c), &challenge, p.UserID)
+			}
+		}
+	}
+	utils.Success(c, challenge)
+}
+
+// Result 挑战结果: 获胜者与奖池分配
+func (cc *ChallengeController) Result(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var challenge models.Challenge
+	if err := database.Tenant(c).Preload("Participants", "status = ?", services.ParticipantAccepted).
+		Preload("Participants.User", userBriefPreload).
+		First(&challenge, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "挑战不存在")
+		return
+	}
+	if challenge.Status == services.ChallengeActive {
+		utils.Fail(c, "挑战尚未结束")
+		return
+	}
+
+	var participant models.ChallengeParticipant
+	if err := database.Tenant(c).Where("challenge_id = ? AND user_id = ?", challenge.ID, userID).
+		First(&participant).Error; err != nil {
+		utils.Fail(c, "挑战不存在")
+		return
+	}
+
+	winners := []models.ChallengeParticipant{}
+	for _, p := range challenge.Participants {
+		if p.IsWinner {
+			winners = append(winners, p)
+		}
+	}
+	utils.Success(c, gin.H{
+		"status":       challenge.Status,
+		"pot":          challenge.Pot,
+		"settledAt":    challenge.SettledAt,
+		"participants": challenge.Participants,
+		"winners":      winners,
+		"mine":         participant,
+	})
+}
+
+// Accept 接受挑战
+func (cc *ChallengeController) Accept(c *gin.Context) {
+	cc.respond(c, true)
+}
+
+// Decline 拒绝挑战
+func (cc *ChallengeController) Decline(c *gin.Context) {
+	cc.respond(c, false)
+}
+
+// respond 处理挑战邀请
+func (cc *ChallengeController) respond(c *gin.Context, accept bool) {
+	userID := middleware.GetCurrentUserID(c)
+	challengeID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	participant, err := services.RespondChallenge(tx, userID, uint(challengeID), accept)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, challengeError(err))
+		return
+	}
+	tx.Commit()
+
+	if accept {
+		utils.SuccessWithMessage(c, "已接受挑战", participant)
+		return
+	}
+	utils.SuccessWithMessage(c, "已拒绝挑战", nil)
+}
+
+// Cancel 取消挑战
+func (cc *ChallengeController) Cancel(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	challengeID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.CancelChallenge(tx, userID, uint(challengeID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, challengeError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "挑战已取消", nil)
+}
+
+// inChallenge 用户是否为挑战参与者(含受邀)
+func inChallenge(challenge *models.Challenge, userID uint) bool {
+	for _, p := range challenge.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// challengeError 转换挑战操作失败原因
+func challengeError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "挑战不存在"
+	case errors.Is(err, services.ErrChallengeParam), errors.Is(err, services.ErrChallengeInvitees),
+		errors.Is(err, services.ErrChallengeFriend), errors.Is(err, services.ErrChallengeTask),
+		errors.Is(err, services.ErrChallengeClosed), errors.Is(err, services.ErrChallengeInvite),
+		errors.Is(err, services.ErrChallengeCancel), errors.Is(err, services.ErrChallengeNotOwner),
+		errors.Is(err, services.ErrInsufficientGold):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
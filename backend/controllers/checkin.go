@@ -0,0 +1,163 @@
+// Package controllers 签到控制器
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckinController 签到控制器
+type CheckinController struct{}
+
+// CheckinCalendarRequest 签到日历保存请求
+type CheckinCalendarRequest struct {
+	Rewards []models.CheckinReward `json:"rewards"`
+}
+
+// MakeupRequest 补签请求
+type MakeupRequest struct {
+	Date string `json:"date" binding:"required"` // 2006-01-02
+}
+
+// Calendar 签到日历配置 (管理端)
+func (cc *CheckinController) Calendar(c *gin.Context) {
+	var rewards []models.CheckinReward
+	database.Tenant(c).Order("day").Find(&rewards)
+	utils.Success(c, rewards)
+}
+
+// SaveCalendar 整体替换签到日历
+func (cc *CheckinController) SaveCalendar(c *gin.Context) {
+	var req CheckinCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	days := map[int]bool{}
+	for _, reward := range req.Rewards {
+		if reward.Day < 1 || reward.Day > 31 || days[reward.Day] {
+			utils.Fail(c, "签到天数需在1-31之间且不能重复")
+			return
+		}
+		days[reward.Day] = true
+	}
+
+	tx := database.Tenant(c).Begin()
+	tx.Where("1 = 1").Delete(&models.CheckinReward{})
+	for _, reward := range req.Rewards {
+		reward.ID = 0
+		if err := tx.Create(&reward).Error; err != nil {
+			tx.Rollback()
+			utils.Fail(c, "保存失败")
+			return
+		}
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "保存成功", nil)
+}
+
+// ===== 用户端接口 =====
+
+// History 签到日历与记录 (H5端)
+func (cc *CheckinController) History(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	month := c.DefaultQuery("month", time.Now().Format("2006-01"))
+	if _, err := time.Parse("2006-01", month); err != nil {
+		utils.Fail(c, "月份格式错误")
+		return
+	}
+
+	var checkins []models.UserCheckin
+	database.Tenant(c).Where("user_id = ? AND month = ?", userID, month).Order("date").Find(&checkins)
+
+	var calendar []models.CheckinReward
+	database.Tenant(c).Order("day").Find(&calendar)
+
+	checkedToday := false
+	today := time.Now().Format("2006-01-02")
+	for _, checkin := range checkins {
+		if checkin.Date == today {
+			checkedToday = true
+		}
+	}
+
+	utils.Success(c, gin.H{
+		"month":           month,
+		"checkins":        checkins,
+		"count":           len(checkins),
+		"checkedToday":    checkedToday,
+		"calendar":        calendar,
+		"makeupCost":      config.AppConfig.Checkin.MakeupCost,
+		"makeupRemaining": services.MakeupRemaining(database.Tenant(c), userID, month),
+	})
+}
+
+// Checkin 今日签到
+func (cc *CheckinController) Checkin(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	tx := database.Tenant(c).Begin()
+	checkin, err := services.Checkin(tx, userID, time.Now(), false)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, checkinError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "签到成功", checkin)
+}
+
+// Makeup 补签
+func (cc *CheckinController) Makeup(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req MakeupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		utils.Fail(c, "日期格式错误")
+		return
+	}
+	now := time.Now()
+	if date.Format("2006-01") != now.Format("2006-01") || date.Format("2006-01-02") >= now.Format("2006-01-02") {
+		utils.Fail(c, services.ErrMakeupDate.Error())
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	checkin, err := services.Checkin(tx, userID, date, true)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, checkinError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "补签成功", checkin)
+}
+
+// checkinError 转换签到失败原因
+func checkinError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrCheckedIn), errors.Is(err, services.ErrMakeupLimit),
+		errors.Is(err, services.ErrMakeupDate), errors.Is(err, services.ErrInsufficientGold):
+		return err.Error()
+	default:
+		return "签到失败"
+	}
+}
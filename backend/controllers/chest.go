@@ -0,0 +1,182 @@
+// Package controllers 宝箱控制器
+package controllers
+
+import (
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChestController 宝箱控制器
+type ChestController struct{}
+
+// DropTableRequest 掉落表保存请求
+type DropTableRequest struct {
+	Drops []models.ChestDrop `json:"drops"`
+}
+
+// DropTable 宝箱掉落表 (管理端)
+func (cc *ChestController) DropTable(c *gin.Context) {
+	// 掉落表没有空间字段, 先确认宝箱属于当前空间
+	var chest models.Reward
+	if err := database.Tenant(c).First(&chest, c.Param("id")).Error; err != nil || chest.Type != services.RewardTypeChest {
+		utils.Fail(c, "宝箱不存在")
+		return
+	}
+
+	var drops []models.ChestDrop
+	database.Tenant(c).Where("chest_id = ?", chest.ID).Order("sort, id").Find(&drops)
+	utils.Success(c, services.DropRates(drops))
+}
+
+// SaveDropTable 整体替换宝箱掉落表
+func (cc *ChestController) SaveDropTable(c *gin.Context) {
+	chestID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var chest models.Reward
+	if err := database.Tenant(c).First(&chest, chestID).Error; err != nil || chest.Type != services.RewardTypeChest {
+		utils.Fail(c, "宝箱不存在")
+		return
+	}
+
+	var req DropTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	for _, drop := range req.Drops {
+		if drop.Name == "" || drop.Weight <= 0 {
+			utils.Fail(c, "掉落名称不能为空且权重必须大于0")
+			return
+		}
+		switch drop.Kind {
+		case services.DropCurrency, services.DropExp:
+			if drop.Amount <= 0 {
+				utils.Fail(c, "货币/经验掉落数量必须大于0")
+				return
+			}
+			if drop.Kind == services.DropCurrency {
+				if err := services.CheckCurrency(database.Tenant(c), drop.Currency); err != nil {
+					utils.Fail(c, "掉落币种不存在或未启用: "+drop.Currency)
+					return
+				}
+			}
+		case services.DropReward, services.DropItem, services.DropBadge:
+		default:
+			utils.Fail(c, "不支持的掉落类型: "+drop.Kind)
+			return
+		}
+	}
+
+	tx := database.Tenant(c).Begin()
+	tx.Where("chest_id = ?", chest.ID).Delete(&models.ChestDrop{})
+	for _, drop := range req.Drops {
+		drop.ID = 0
+		drop.ChestID = chest.ID
+		if err := tx.Create(&drop).Error; err != nil {
+			tx.Rollback()
+			utils.Fail(c, "保存失败")
+			return
+		}
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "保存成功", nil)
+}
+
+// OpenList 开箱记录 (管理端)
+func (cc *ChestController) OpenList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	userID := c.Query("userId")
+	chestID := c.Query("chestId")
+
+	var opens []models.ChestOpen
+	var total int64
+
+	// 开箱记录没有空间字段, 按当前空间的用户限定
+	query := database.Tenant(c).Model(&models.ChestOpen{}).
+		Where("user_id IN (?)", database.Tenant(c).Model(&models.SysUser{}).Select("id"))
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if chestID != "" {
+		query = query.Where("chest_id = ?", chestID)
+	}
+
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&opens)
+
+	utils.PageSuccess(c, opens, total, page, pageSize)
+}
+
+// ===== 用户端接口 =====
+
+// DropRates 宝箱掉落概率公示 (H5端)
+func (cc *ChestController) DropRates(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var chest models.Reward
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		First(&chest, c.Param("id")).Error; err != nil || chest.Type != services.RewardTypeChest {
+		utils.Fail(c, "宝箱不存在")
+		return
+	}
+
+	drops := services.LoadDropTable(database.Tenant(c), chest.ID)
+	utils.Success(c, services.DropRates(drops))
+}
+
+// UserOpenList 我的开箱记录 (H5端)
+func (cc *ChestController) UserOpenList(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	var opens []models.ChestOpen
+	var total int64
+
+	query := database.Tenant(c).Model(&models.ChestOpen{}).Where("user_id = ?", userID)
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&opens)
+
+	utils.PageSuccess(c, opens, total, page, pageSize)
+}
+
+// Verify 验证开箱结果
+func (cc *ChestController) Verify(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	openID := c.Param("id")
+
+	var open models.ChestOpen
+	if err := database.Tenant(c).Where("id = ? AND user_id = ?", openID, userID).First(&open).Error; err != nil {
+		utils.Fail(c, "开箱记录不存在")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"open":         open,
+		"verification": services.VerifyChestOpen(&open),
+	})
+}
+
+// UserItems 我的背包 (H5端)
+func (cc *ChestController) UserItems(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	kind := c.Query("kind")
+
+	query := database.Tenant(c).Where("user_id = ?", userID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var items []models.UserItem
+	query.Order("id desc").Find(&items)
+	utils.Success(c, items)
+}
@@ -0,0 +1,101 @@
+// Package controllers 币种控制器
+package controllers
+
+import (
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrencyController 币种控制器
+type CurrencyController struct{}
+
+// List 币种列表 (管理端)
+func (cc *CurrencyController) List(c *gin.Context) {
+	var currencies []models.Currency
+	database.Tenant(c).Order("sort, id").Find(&currencies)
+	utils.Success(c, currencies)
+}
+
+// Create 创建币种
+func (cc *CurrencyController) Create(c *gin.Context) {
+	var currency models.Currency
+	if err := c.ShouldBindJSON(&currency); err != nil || currency.Code == "" {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	// 流水类型为 <币种>_out, 需能写入流水类型字段
+	if len(currency.Code) > 16 {
+		utils.Fail(c, "币种代码最多16个字符")
+		return
+	}
+	if currency.Code == services.CurrencyExp {
+		utils.Fail(c, "币种代码已被占用")
+		return
+	}
+
+	var count int64
+	database.Tenant(c).Model(&models.Currency{}).Where("code = ?", currency.Code).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "币种代码已存在")
+		return
+	}
+
+	if err := database.Tenant(c).Create(&currency).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", currency)
+}
+
+// Update 更新币种 (代码不可修改)
+func (cc *CurrencyController) Update(c *gin.Context) {
+	id := c.Param("id")
+	var currency models.Currency
+	if err := database.Tenant(c).First(&currency, id).Error; err != nil {
+		utils.Fail(c, "币种不存在")
+		return
+	}
+
+	var updateData models.Currency
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	// 金币为基础币种，不允许停用
+	updates := map[string]interface{}{
+		"name":      updateData.Name,
+		"icon":      updateData.Icon,
+		"sort":      updateData.Sort,
+		"is_active": updateData.IsActive || currency.Code == services.CurrencyGold,
+	}
+	database.Tenant(c).Model(&currency).Updates(updates)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// ===== 用户端接口 =====
+
+// UserWallet 用户钱包 (H5端)
+func (cc *CurrencyController) UserWallet(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var currencies []models.Currency
+	database.Tenant(c).Where("is_active = ?", true).Order("sort, id").Find(&currencies)
+
+	balances, err := services.Balances(database.Tenant(c), userID)
+	if err != nil {
+		utils.Fail(c, "用户不存在")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"currencies": currencies,
+		"balances":   balances,
+	})
+}
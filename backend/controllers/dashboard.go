@@ -8,6 +8,7 @@ import (
 	"life-rpg/database"
 	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/services"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -20,29 +21,30 @@ type DashboardController struct{}
 func (dc *DashboardController) Stats(c *gin.Context) {
 	// 用户总数
 	var userCount int64
-	database.DB.Model(&models.SysUser{}).Count(&userCount)
+	database.Tenant(c).Model(&models.SysUser{}).Count(&userCount)
 
 	// 今日产生金币
 	today := time.Now().Format("2006-01-02")
 	var todayGold int64
-	database.DB.Model(&models.UserLog{}).
+	database.Tenant(c).Model(&models.UserLog{}).
 		Where("type = ? AND DATE(created_at) = ?", "gold_in", today).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&todayGold)
 
-	// 今日完成任务数
+	// 今日完成任务数, 完成记录按用户归属空间
+	tenantUsers := database.Tenant(c).Model(&models.SysUser{}).Select("id")
 	var todayTasks int64
-	database.DB.Model(&models.UserTask{}).
-		Where("DATE(completed_at) = ?", today).
+	database.Tenant(c).Model(&models.UserTask{}).
+		Where("DATE(completed_at) = ? AND status = ? AND user_id IN (?)", today, services.TaskApproved, tenantUsers).
 		Count(&todayTasks)
 
 	// 活跃任务数
 	var activeTaskCount int64
-	database.DB.Model(&models.Task{}).Where("is_active = ?", true).Count(&activeTaskCount)
+	database.Tenant(c).Model(&models.Task{}).Where("is_active = ?", true).Count(&activeTaskCount)
 
 	// 活跃奖励数
 	var activeRewardCount int64
-	database.DB.Model(&models.Reward{}).Where("is_active = ?", true).Count(&activeRewardCount)
+	database.Tenant(c).Model(&models.Reward{}).Where("is_active = ?", true).Count(&activeRewardCount)
 
 	// 最近7天每日金币产出
 	var dailyGoldStats []struct {
@@ -52,7 +54,7 @@ func (dc *DashboardController) Stats(c *gin.Context) {
 	for i := 6; i >= 0; i-- {
 		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
 		var gold int
-		database.DB.Model(&models.UserLog{}).
+		database.Tenant(c).Model(&models.UserLog{}).
 			Where("type = ? AND DATE(created_at) = ?", "gold_in", date).
 			Select("COALESCE(SUM(amount), 0)").
 			Scan(&gold)
@@ -70,8 +72,8 @@ func (dc *DashboardController) Stats(c *gin.Context) {
 	for i := 6; i >= 0; i-- {
 		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
 		var count int64
-		database.DB.Model(&models.UserTask{}).
-			Where("DATE(completed_at) = ?", date).
+		database.Tenant(c).Model(&models.UserTask{}).
+			Where("DATE(completed_at) = ? AND status = ? AND user_id IN (?)", date, services.TaskApproved, tenantUsers).
 			Count(&count)
 		dailyTaskStats = append(dailyTaskStats, struct {
 			Date  string `json:"date"`
@@ -79,6 +81,33 @@ func (dc *DashboardController) Stats(c *gin.Context) {
 		}{Date: date, Count: count})
 	}
 
+	// 最近7天每日金币产出与消耗 (排除转账、储蓄等内部转移, 退款冲减消耗)
+	var dailyEconomyStats []struct {
+		Date   string `json:"date"`
+		Minted int    `json:"minted"`
+		Spent  int    `json:"spent"`
+	}
+	for i := 6; i >= 0; i-- {
+		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
+		var stat struct {
+			Minted   int
+			Spent    int
+			Refunded int
+		}
+		database.Tenant(c).Model(&models.UserLog{}).
+			Where("DATE(created_at) = ? AND ref_type NOT IN ?", date, services.InternalRefTypes).
+			Select("COALESCE(SUM(CASE WHEN type = ? AND ref_type <> ? THEN amount ELSE 0 END), 0) AS minted, "+
+				"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS spent, "+
+				"COALESCE(SUM(CASE WHEN type = ? AND ref_type = ? THEN amount ELSE 0 END), 0) AS refunded",
+				services.LogGoldIn, "refund", services.LogGoldOut, services.LogGoldIn, "refund").
+			Scan(&stat)
+		dailyEconomyStats = append(dailyEconomyStats, struct {
+			Date   string `json:"date"`
+			Minted int    `json:"minted"`
+			Spent  int    `json:"spent"`
+		}{Date: date, Minted: stat.Minted, Spent: stat.Spent - stat.Refunded})
+	}
+
 	utils.Success(c, gin.H{
 		"userCount":         userCount,
 		"todayGold":         todayGold,
@@ -87,6 +116,7 @@ func (dc *DashboardController) Stats(c *gin.Context) {
 		"activeRewardCount": activeRewardCount,
 		"dailyGoldStats":    dailyGoldStats,
 		"dailyTaskStats":    dailyTaskStats,
+		"dailyEconomyStats": dailyEconomyStats,
 	})
 }
 
@@ -97,15 +127,19 @@ func (dc *DashboardController) UserProfile(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 
 	var user models.SysUser
-	database.DB.Preload("Role").First(&user, userID)
+	database.Tenant(c).Preload("Role").First(&user, userID)
 
 	// 计算升级所需经验
 	nextLevelExp := user.Level * 100
 	currentLevelExp := (user.Level - 1) * user.Level / 2 * 100
 	expProgress := user.Exp - currentLevelExp
 
+	// 全部币种余额
+	wallet, _ := services.Balances(database.Tenant(c), userID)
+
 	utils.Success(c, gin.H{
 		"user":          user,
+		"wallet":        wallet,
 		"nextLevelExp":  nextLevelExp,
 		"expProgress":   expProgress,
 		"expPercentage": float64(expProgress) / float64(nextLevelExp) * 100,
@@ -117,15 +151,19 @@ func (dc *DashboardController) UserLogs(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
-	logType := c.Query("type") // gold_in, gold_out, exp_in
+	logType := c.Query("type")      // gold_in, gold_out, exp_in, gem_in ...
+	currency := c.Query("currency") // gold, gem, exp ...
 
 	var logs []models.UserLog
 	var total int64
 
-	query := database.DB.Model(&models.UserLog{}).Where("user_id = ?", userID)
+	query := database.Tenant(c).Model(&models.UserLog{}).Where("user_id = ?", userID)
 	if logType != "" {
 		query = query.Where("type = ?", logType)
 	}
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
 
 	query.Count(&total)
 	query.Order("created_at desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs)
@@ -133,10 +171,18 @@ func (dc *DashboardController) UserLogs(c *gin.Context) {
 	utils.PageSuccess(c, logs, total, page, pageSize)
 }
 
-// ThemeConfig 获取H5主题配置
+// ThemeConfig 获取H5主题配置, 参数 tenant 为空间标识, 为空时返回默认空间的配置
 func (dc *DashboardController) ThemeConfig(c *gin.Context) {
+	tenantID := database.DefaultTenantID
+	if code := c.Query("tenant"); code != "" {
+		var tenant models.Tenant
+		if err := database.DB.Where("code = ?", code).First(&tenant).Error; err == nil {
+			tenantID = tenant.ID
+		}
+	}
+
 	var theme models.ThemeConfig
-	database.DB.First(&theme)
+	database.DB.Where("tenant_id = ?", tenantID).First(&theme)
 
 	// 如果没有配置则返回默认值
 	if theme.ID == 0 {
@@ -161,14 +207,14 @@ func (dc *DashboardController) UpdateThemeConfig(c *gin.Context) {
 
 	// 获取现有配置
 	var existing models.ThemeConfig
-	database.DB.First(&existing)
+	database.Tenant(c).First(&existing)
 
 	if existing.ID == 0 {
 		// 创建新配置
-		database.DB.Create(&theme)
+		database.Tenant(c).Create(&theme)
 	} else {
 		// 更新配置
-		database.DB.Model(&existing).Updates(theme)
+		database.Tenant(c).Model(&existing).Updates(theme)
 	}
 
 	utils.SuccessWithMessage(c, "更新成功", nil)
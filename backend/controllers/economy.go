@@ -0,0 +1,105 @@
+// Package controllers 经济调控控制器
+package controllers
+
+import (
+	"life-rpg/database"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EconomyController 经济调控控制器
+type EconomyController struct{}
+
+// CapList 每日收益上限列表
+func (ec *EconomyController) CapList(c *gin.Context) {
+	var caps []models.EarningCap
+	database.Tenant(c).Order("category").Find(&caps)
+	utils.Success(c, caps)
+}
+
+// CreateCap 创建收益上限
+func (ec *EconomyController) CreateCap(c *gin.Context) {
+	var limit models.EarningCap
+	if err := c.ShouldBindJSON(&limit); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateCap(&limit); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	var count int64
+	database.Tenant(c).Model(&models.EarningCap{}).Where("category = ?", limit.Category).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "该分类已配置上限")
+		return
+	}
+
+	if err := database.Tenant(c).Create(&limit).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", limit)
+}
+
+// UpdateCap 更新收益上限
+func (ec *EconomyController) UpdateCap(c *gin.Context) {
+	id := c.Param("id")
+	var limit models.EarningCap
+	if err := database.Tenant(c).First(&limit, id).Error; err != nil {
+		utils.Fail(c, "上限配置不存在")
+		return
+	}
+
+	var updateData models.EarningCap
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	updateData.Category = limit.Category
+	if msg := validateCap(&updateData); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	// 上限为0表示不限, 需要允许写入零值
+	database.Tenant(c).Model(&limit).Select("max_gold", "max_exp", "overflow", "convert_currency", "convert_percent", "is_active").
+		Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// DeleteCap 删除收益上限
+func (ec *EconomyController) DeleteCap(c *gin.Context) {
+	id := c.Param("id")
+	if err := database.Tenant(c).Delete(&models.EarningCap{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// validateCap 校验收益上限参数
+func validateCap(limit *models.EarningCap) string {
+	if limit.MaxGold < 0 || limit.MaxExp < 0 {
+		return "上限不能为负数"
+	}
+	switch limit.Overflow {
+	case "", services.OverflowDiscard:
+		limit.Overflow = services.OverflowDiscard
+	case services.OverflowConvert:
+		if limit.ConvertCurrency == "" || limit.ConvertCurrency == services.CurrencyGold {
+			return "请选择金币以外的转换币种"
+		}
+		if limit.ConvertPercent <= 0 || limit.ConvertPercent > 100 {
+			return "转换比例需在1-100之间"
+		}
+	default:
+		return "不支持的溢出处理方式"
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+// Package controllers 实时事件控制器
+package controllers
+
+import (
+	"io"
+	"time"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/realtime"
+	"life-rpg/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval 心跳间隔, 避免代理因空闲断开连接
+const heartbeatInterval = 30 * time.Second
+
+// EventController 实时事件控制器
+type EventController struct{}
+
+// Stream 实时事件流 (SSE): 余额、等级、通知和公告
+// 连接建立后先推送一次 ready 事件, 携带当前余额和未读通知数
+func (ec *EventController) Stream(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	tenantID := middleware.GetCurrentTenantID(c)
+
+	sub := realtime.Subscribe(realtime.UserTopic(userID), realtime.TenantTopic(tenantID))
+	defer sub.Close()
+
+	balances, _ := services.Balances(database.Tenant(c), userID)
+	ready := gin.H{
+		"balances":    balances,
+		"unreadCount": services.UnreadCount(database.Tenant(c), userID),
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ready", ready)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"life-rpg/database"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeResult 模拟查询返回的列和行
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeHandler 按SQL返回查询结果, 返回nil表示空结果
+type fakeHandler func(query string, args []driver.NamedValue) (*fakeResult, error)
+
+// unquotedKey MySQL中 key 为保留字, 未加引号时语法错误
+var unquotedKey = regexp.MustCompile("(^|[^`_.a-z])key\\s*=")
+
+var (
+	fakeOnce     sync.Once
+	fakeHandlers sync.Map // dsn -> fakeHandler
+)
+
+// openFakeDB 以内存中的假驱动替换 database.DB, 记录执行过的SQL
+func openFakeDB(t *testing.T, handler fakeHandler) *[]string {
+	t.Helper()
+	fakeOnce.Do(func() { sql.Register("fakemysql", fakeDriver{}) })
+
+	var executed []string
+	dsn := t.Name()
+	fakeHandlers.Store(dsn, fakeHandler(func(query string, args []driver.NamedValue) (*fakeResult, error) {
+		executed = append(executed, query)
+		if unquotedKey.MatchString(query) {
+			return nil, fmt.Errorf("Error 1064: You have an error in your SQL syntax near 'key = ?'")
+		}
+		return handler(query, args)
+	}))
+	db, err := gorm.Open(mysql.New(mysql.Config{DriverName: "fakemysql", DSN: dsn, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开假数据库失败: %v", err)
+	}
+
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = prev
+		fakeHandlers.Delete(dsn)
+	})
+	return &executed
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	handler, ok := fakeHandlers.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("未注册的假数据库: %s", dsn)
+	}
+	return &fakeConn{handler: handler.(fakeHandler)}, nil
+}
+
+type fakeConn struct {
+	handler fakeHandler
+	lastID  int64
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *fakeConn) Commit() error                       { return nil }
+func (c *fakeConn) Rollback() error                     { return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.handler(query, args); err != nil {
+		return nil, err
+	}
+	c.lastID++
+	return fakeExecResult(c.lastID), nil
+}
+
+// fakeExecResult 写入结果, 自增ID按连接递增
+type fakeExecResult int64
+
+func (r fakeExecResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r fakeExecResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.handler(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &fakeResult{columns: []string{"id"}}
+	}
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result *fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+// isSelect 是否为指定表的查询
+func isSelect(query, table string) bool {
+	return strings.HasPrefix(query, "SELECT") && strings.Contains(query, "FROM `"+table+"`")
+}
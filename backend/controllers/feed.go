@@ -0,0 +1,258 @@
+// Package controllers 好友动态控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedController 动态控制器
+type FeedController struct{}
+
+// CommentRequest 评论请求
+type CommentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// AdminList 动态列表 (管理端)
+func (fc *FeedController) AdminList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	userID := c.Query("userId")
+	kind := c.Query("kind")
+
+	var events []models.FeedEvent
+	var total int64
+
+	query := database.Tenant(c).Model(&models.FeedEvent{})
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	query.Count(&total)
+	query.Preload("User", userBriefPreload).Order("id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&events)
+
+	utils.PageSuccess(c, events, total, page, pageSize)
+}
+
+// AdminDelete 删除动态及其互动 (管理端)
+func (fc *FeedController) AdminDelete(c *gin.Context) {
+	var event models.FeedEvent
+	if err := database.Tenant(c).First(&event, c.Param("id")).Error; err != nil {
+		utils.Fail(c, services.ErrFeedNotFound.Error())
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	tx.Where("event_id = ?", event.ID).Delete(&models.FeedReaction{})
+	tx.Where("event_id = ?", event.ID).Delete(&models.FeedComment{})
+	if err := tx.Delete(&event).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "删除失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// AdminDeleteComment 删除评论 (管理端)
+func (fc *FeedController) AdminDeleteComment(c *gin.Context) {
+	commentID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.DeleteComment(tx, 0, uint(commentID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, feedError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ===== 用户端接口 =====
+
+// List 好友动态 (H5端), 包含自己的动态, 按发布者隐私设置过滤
+func (fc *FeedController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	// 动态设为仅自己可见的好友不出现在信息流中
+	authorIDs := []uint{userID}
+	for _, id := range services.FriendIDs(database.Tenant(c), userID) {
+		if services.GetPrivacy(database.Tenant(c), id).Feed != services.VisiblePrivate {
+			authorIDs = append(authorIDs, id)
+		}
+	}
+
+	var events []models.FeedEvent
+	var total int64
+
+	query := database.Tenant(c).Model(&models.FeedEvent{}).Where("user_id IN ?", authorIDs)
+	query.Count(&total)
+	query.Preload("User", userBriefPreload).Order("id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&events)
+
+	eventIDs := make([]uint, 0, len(events))
+	for _, e := range events {
+		eventIDs = append(eventIDs, e.ID)
+	}
+	var cheered []uint
+	if len(eventIDs) > 0 {
+		database.Tenant(c).Model(&models.FeedReaction{}).
+			Where("user_id = ? AND event_id IN ?", userID, eventIDs).
+			Pluck("event_id", &cheered)
+	}
+	cheeredSet := make(map[uint]bool, len(cheered))
+	for _, id := range cheered {
+		cheeredSet[id] = true
+	}
+
+	list := make([]gin.H, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		if !services.CanSeeFeedEvent(database.Tenant(c), userID, event) {
+			continue
+		}
+		list = append(list, gin.H{
+			"event":   event,
+			"cheered": cheeredSet[event.ID],
+		})
+	}
+
+	utils.PageSuccess(c, list, total, page, pageSize)
+}
+
+// Cheer 为动态加油
+func (fc *FeedController) Cheer(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	eventID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.CheerEvent(tx, userID, uint(eventID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, feedError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "加油成功", nil)
+}
+
+// Uncheer 取消加油
+func (fc *FeedController) Uncheer(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	eventID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.UncheerEvent(tx, userID, uint(eventID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, feedError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已取消", nil)
+}
+
+// Cheers 动态的加油列表
+func (fc *FeedController) Cheers(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var event models.FeedEvent
+	if err := database.Tenant(c).First(&event, c.Param("id")).Error; err != nil ||
+		!services.CanSeeFeedEvent(database.Tenant(c), userID, &event) {
+		utils.Fail(c, services.ErrFeedNotFound.Error())
+		return
+	}
+
+	var reactions []models.FeedReaction
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("event_id = ?", event.ID).
+		Order("id desc").
+		Find(&reactions)
+	utils.Success(c, reactions)
+}
+
+// Comments 动态评论列表
+func (fc *FeedController) Comments(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var event models.FeedEvent
+	if err := database.Tenant(c).First(&event, c.Param("id")).Error; err != nil ||
+		!services.CanSeeFeedEvent(database.Tenant(c), userID, &event) {
+		utils.Fail(c, services.ErrFeedNotFound.Error())
+		return
+	}
+
+	var comments []models.FeedComment
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("event_id = ?", event.ID).
+		Order("id").
+		Find(&comments)
+	utils.Success(c, comments)
+}
+
+// Comment 评论动态
+func (fc *FeedController) Comment(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	eventID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	comment, err := services.AddComment(tx, userID, uint(eventID), req.Content)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, feedError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "评论成功", comment)
+}
+
+// DeleteComment 删除评论
+func (fc *FeedController) DeleteComment(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	commentID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.DeleteComment(tx, userID, uint(commentID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, feedError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// feedError 转换动态操作失败原因
+func feedError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrFeedNotFound), errors.Is(err, services.ErrFeedCheered),
+		errors.Is(err, services.ErrFeedComment), errors.Is(err, services.ErrFeedCommentOwner):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
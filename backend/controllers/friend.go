@@ -0,0 +1,294 @@
+// Package controllers 好友与隐私控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FriendController 好友控制器
+type FriendController struct{}
+
+// FriendRequestBody 好友申请请求
+type FriendRequestBody struct {
+	Username string `json:"username" binding:"required"`
+	Message  string `json:"message"`
+}
+
+// BlockRequest 拉黑请求
+type BlockRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+// PrivacyRequest 隐私设置请求
+type PrivacyRequest struct {
+	Level         string `json:"level" binding:"required"`
+	Streak        string `json:"streak" binding:"required"`
+	Logs          string `json:"logs" binding:"required"`
+	Feed          string `json:"feed"` // 为空时不修改
+	AllowRequests bool   `json:"allowRequests"`
+}
+
+// List 好友列表, 等级与连续打卡按好友的隐私设置展示
+func (fc *FriendController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var friendships []models.Friendship
+	database.Tenant(c).Preload("User", userBriefPreload).Preload("Friend", userBriefPreload).
+		Where("(user_id = ? OR friend_id = ?) AND status = ?", userID, userID, services.FriendAccepted).
+		Order("accepted_at desc").
+		Find(&friendships)
+
+	list := make([]gin.H, 0, len(friendships))
+	for _, f := range friendships {
+		friend := f.Friend
+		if f.FriendID == userID {
+			friend = f.User
+		}
+		if friend == nil {
+			continue
+		}
+		list = append(list, publicProfile(userID, friend, gin.H{
+			"friendshipId": f.ID,
+			"since":        f.AcceptedAt,
+		}))
+	}
+	utils.Success(c, list)
+}
+
+// Requests 好友申请: 收到的与发出的待处理申请
+func (fc *FriendController) Requests(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var incoming, outgoing []models.Friendship
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("friend_id = ? AND status = ?", userID, services.FriendPending).
+		Order("id desc").Find(&incoming)
+	database.Tenant(c).Preload("Friend", userBriefPreload).
+		Where("user_id = ? AND status = ?", userID, services.FriendPending).
+		Order("id desc").Find(&outgoing)
+
+	utils.Success(c, gin.H{
+		"incoming": incoming,
+		"outgoing": outgoing,
+	})
+}
+
+// SendRequest 发送好友申请
+func (fc *FriendController) SendRequest(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req FriendRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var target models.SysUser
+	if err := database.Tenant(c).Where("username = ?", req.Username).First(&target).Error; err != nil {
+		utils.Fail(c, services.ErrFriendUser.Error())
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	friendship, err := services.SendFriendRequest(tx, userID, target.ID, req.Message)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, friendError(err))
+		return
+	}
+	tx.Commit()
+
+	message := "申请已发送"
+	if friendship.Status == services.FriendAccepted {
+		message = "已成为好友"
+	}
+	utils.SuccessWithMessage(c, message, friendship)
+}
+
+// Accept 通过好友申请
+func (fc *FriendController) Accept(c *gin.Context) {
+	fc.respond(c, true)
+}
+
+// Reject 拒绝好友申请
+func (fc *FriendController) Reject(c *gin.Context) {
+	fc.respond(c, false)
+}
+
+// respond 处理好友申请
+func (fc *FriendController) respond(c *gin.Context, accept bool) {
+	userID := middleware.GetCurrentUserID(c)
+	requestID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	friendship, err := services.RespondFriendRequest(tx, userID, uint(requestID), accept)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, friendError(err))
+		return
+	}
+	tx.Commit()
+
+	if accept {
+		utils.SuccessWithMessage(c, "已成为好友", friendship)
+		return
+	}
+	utils.SuccessWithMessage(c, "已拒绝", nil)
+}
+
+// Remove 删除好友或撤回申请
+func (fc *FriendController) Remove(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	friendID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := services.RemoveFriend(database.Tenant(c), userID, uint(friendID)); err != nil {
+		utils.Fail(c, friendError(err))
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// Blocks 黑名单
+func (fc *FriendController) Blocks(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var blocks []models.UserBlock
+	database.Tenant(c).Preload("Blocked", userBriefPreload).
+		Where("user_id = ?", userID).
+		Order("id desc").
+		Find(&blocks)
+	utils.Success(c, blocks)
+}
+
+// Block 拉黑用户
+func (fc *FriendController) Block(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req BlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	if err := services.BlockUser(tx, userID, req.UserID); err != nil {
+		tx.Rollback()
+		utils.Fail(c, friendError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已拉黑", nil)
+}
+
+// Unblock 取消拉黑
+func (fc *FriendController) Unblock(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	blockedID := c.Param("id")
+
+	database.Tenant(c).Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&models.UserBlock{})
+	utils.SuccessWithMessage(c, "已取消拉黑", nil)
+}
+
+// Profile 查看用户资料, 按对方隐私设置返回等级、连续打卡与最近流水
+func (fc *FriendController) Profile(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	targetID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var target models.SysUser
+	if err := database.Tenant(c).Select("id", "username", "nickname", "avatar", "level", "exp", "status").
+		First(&target, targetID).Error; err != nil || services.IsBlocked(database.Tenant(c), userID, target.ID) {
+		utils.Fail(c, "用户不存在")
+		return
+	}
+
+	profile := publicProfile(userID, &target, gin.H{
+		"isFriend": services.AreFriends(database.Tenant(c), userID, target.ID),
+	})
+	if services.CanView(database.Tenant(c), userID, target.ID, services.GetPrivacy(database.Tenant(c), target.ID).Logs) {
+		var logs []models.UserLog
+		database.Tenant(c).Where("user_id = ?", target.ID).Order("id desc").Limit(20).Find(&logs)
+		profile["logs"] = logs
+	}
+	utils.Success(c, profile)
+}
+
+// Privacy 我的隐私设置
+func (fc *FriendController) Privacy(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	utils.Success(c, services.GetPrivacy(database.Tenant(c), userID))
+}
+
+// UpdatePrivacy 更新隐私设置
+func (fc *FriendController) UpdatePrivacy(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req PrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if req.Feed == "" {
+		req.Feed = services.GetPrivacy(database.Tenant(c), userID).Feed
+	}
+	if err := services.CheckVisibility(req.Level, req.Streak, req.Logs, req.Feed); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	var privacy models.UserPrivacy
+	database.Tenant(c).Where("user_id = ?", userID).FirstOrCreate(&privacy, models.UserPrivacy{UserID: userID})
+	database.Tenant(c).Model(&privacy).Updates(map[string]interface{}{
+		"level":          req.Level,
+		"streak":         req.Streak,
+		"logs":           req.Logs,
+		"feed":           req.Feed,
+		"allow_requests": req.AllowRequests,
+	})
+	utils.SuccessWithMessage(c, "保存成功", nil)
+}
+
+// publicProfile 按隐私设置组装用户公开资料, 无权查看的字段返回 nil
+func publicProfile(viewerID uint, user *models.SysUser, extra gin.H) gin.H {
+	privacy := services.GetPrivacy(database.DB, user.ID)
+	profile := gin.H{
+		"id":       user.ID,
+		"username": user.Username,
+		"nickname": user.Nickname,
+		"avatar":   user.Avatar,
+		"level":    nil,
+		"streak":   nil,
+	}
+	if services.CanView(database.DB, viewerID, user.ID, privacy.Level) {
+		profile["level"] = user.Level
+	}
+	if services.CanView(database.DB, viewerID, user.ID, privacy.Streak) {
+		profile["streak"] = services.TaskStreak(database.DB, user.ID)
+	}
+	for k, v := range extra {
+		profile[k] = v
+	}
+	return profile
+}
+
+// friendError 转换好友操作失败原因
+func friendError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrFriendSelf), errors.Is(err, services.ErrFriendUser),
+		errors.Is(err, services.ErrFriendExists), errors.Is(err, services.ErrFriendBlocked),
+		errors.Is(err, services.ErrFriendRequest), errors.Is(err, services.ErrFriendNotFound),
+		errors.Is(err, services.ErrBlockSelf), errors.Is(err, services.ErrFriendNotAllowed):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
@@ -0,0 +1,259 @@
+// Package controllers 心愿储蓄控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GoalController 心愿储蓄控制器
+type GoalController struct{}
+
+// GoalRequest 创建/更新心愿请求
+type GoalRequest struct {
+	RewardID    uint `json:"rewardId"`
+	AutoPercent int  `json:"autoPercent"`
+}
+
+// GoalAmountRequest 存取金额请求
+type GoalAmountRequest struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+// goalProgress 心愿及进度
+type goalProgress struct {
+	models.SavingsGoal
+	Cost       int     `json:"cost"`
+	Percentage float64 `json:"percentage"`
+	Funded     bool    `json:"funded"`
+}
+
+// List 我的心愿列表
+func (gc *GoalController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	status := c.DefaultQuery("status", services.GoalActive)
+
+	var goals []models.SavingsGoal
+	database.Tenant(c).Preload("Reward", services.Unscoped).
+		Where("user_id = ? AND status = ?", userID, status).
+		Order("id desc").
+		Find(&goals)
+
+	result := make([]goalProgress, 0, len(goals))
+	for _, goal := range goals {
+		item := goalProgress{SavingsGoal: goal}
+		if goal.Reward != nil {
+			item.Cost = goal.Reward.Cost
+			item.Funded = goal.Saved >= goal.Reward.Cost
+			if goal.Reward.Cost > 0 {
+				item.Percentage = float64(goal.Saved) / float64(goal.Reward.Cost) * 100
+			} else {
+				item.Percentage = 100
+			}
+		}
+		result = append(result, item)
+	}
+
+	utils.Success(c, result)
+}
+
+// Create 创建心愿
+func (gc *GoalController) Create(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var reward models.Reward
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		Where("is_active = ?", true).First(&reward, req.RewardID).Error; err != nil {
+		utils.Fail(c, "奖励不存在")
+		return
+	}
+	if reward.Type == services.RewardTypeChest {
+		utils.Fail(c, "宝箱不支持加入心愿单")
+		return
+	}
+
+	var count int64
+	database.Tenant(c).Model(&models.SavingsGoal{}).
+		Where("user_id = ? AND reward_id = ? AND status = ?", userID, req.RewardID, services.GoalActive).
+		Count(&count)
+	if count > 0 {
+		utils.Fail(c, "该奖励已在心愿单中")
+		return
+	}
+
+	if err := services.CheckAutoPercent(database.Tenant(c), userID, 0, req.AutoPercent); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	goal := models.SavingsGoal{
+		UserID:      userID,
+		RewardID:    reward.ID,
+		Currency:    reward.Currency,
+		AutoPercent: req.AutoPercent,
+		Status:      services.GoalActive,
+	}
+	if err := database.Tenant(c).Create(&goal).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已加入心愿单", goal)
+}
+
+// Update 修改自动储蓄比例
+func (gc *GoalController) Update(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	goalID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var goal models.SavingsGoal
+	if err := database.Tenant(c).Where("id = ? AND user_id = ? AND status = ?", goalID, userID, services.GoalActive).
+		First(&goal).Error; err != nil {
+		utils.Fail(c, "心愿不存在")
+		return
+	}
+	if err := services.CheckAutoPercent(database.Tenant(c), userID, goal.ID, req.AutoPercent); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	database.Tenant(c).Model(&goal).Update("auto_percent", req.AutoPercent)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// Deposit 存入心愿
+func (gc *GoalController) Deposit(c *gin.Context) {
+	gc.transfer(c, 1)
+}
+
+// Withdraw 从心愿取出
+func (gc *GoalController) Withdraw(c *gin.Context) {
+	gc.transfer(c, -1)
+}
+
+// transfer 在余额与心愿之间转移, sign 为1存入 -1取出
+func (gc *GoalController) transfer(c *gin.Context, sign int) {
+	userID := middleware.GetCurrentUserID(c)
+	goalID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req GoalAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "金额必须大于0")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	goal, err := services.LockGoal(tx, userID, uint(goalID))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, goalError(err))
+		return
+	}
+
+	description := "存入心愿: " + goal.Reward.Title
+	if sign < 0 {
+		description = "取出心愿储蓄: " + goal.Reward.Title
+	}
+	if err := services.DepositGoal(tx, goal, sign*req.Amount, description); err != nil {
+		tx.Rollback()
+		utils.Fail(c, goalError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.Success(c, goal)
+}
+
+// Purchase 使用心愿储蓄兑换
+func (gc *GoalController) Purchase(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	goalID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	goal, err := services.LockGoal(tx, userID, uint(goalID))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, goalError(err))
+		return
+	}
+	if !goal.Reward.IsActive || goal.Reward.DeletedAt.Valid {
+		tx.Rollback()
+		utils.Fail(c, "奖励已下架")
+		return
+	}
+
+	newBalance, err := services.PurchaseGoal(tx, goal)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, goalError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "兑换成功", gin.H{
+		"cost":       goal.Reward.Cost,
+		"currency":   goal.Currency,
+		"newBalance": newBalance,
+		"reward":     goal.Reward.Title,
+	})
+}
+
+// Cancel 取消心愿, 储蓄退回余额
+func (gc *GoalController) Cancel(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	goalID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	goal, err := services.LockGoal(tx, userID, uint(goalID))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, goalError(err))
+		return
+	}
+	if goal.Saved > 0 {
+		if err := services.DepositGoal(tx, goal, -goal.Saved, "取消心愿, 储蓄退回: "+goal.Reward.Title); err != nil {
+			tx.Rollback()
+			utils.Fail(c, goalError(err))
+			return
+		}
+	}
+	tx.Model(goal).Update("status", services.GoalCancelled)
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已取消心愿", nil)
+}
+
+// goalError 转换心愿操作失败原因
+func goalError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "心愿不存在"
+	case errors.Is(err, services.ErrGoalNotActive), errors.Is(err, services.ErrGoalNotFunded),
+		errors.Is(err, services.ErrGoalOverSaved), errors.Is(err, services.ErrInsufficientGold),
+		errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrOutOfStock):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
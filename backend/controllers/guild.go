@@ -0,0 +1,369 @@
+// Package controllers 公会控制器
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuildController 公会控制器
+type GuildController struct{}
+
+// GuildRequest 创建/修改公会请求
+type GuildRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	IsOpen      bool   `json:"isOpen"`
+	MemberLimit int    `json:"memberLimit"`
+	GoalTarget  int    `json:"goalTarget"`
+	GoalGold    int    `json:"goalGold"`
+	GoalExp     int    `json:"goalExp"`
+}
+
+// GuildRoleRequest 调整成员角色请求
+type GuildRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// AdminList 公会列表 (管理端)
+func (gc *GuildController) AdminList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	name := c.Query("name")
+
+	var guilds []models.Guild
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Guild{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	query.Count(&total)
+	query.Preload("Leader", userBriefPreload).Order("id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&guilds)
+
+	utils.PageSuccess(c, guilds, total, page, pageSize)
+}
+
+// AdminDelete 解散公会 (管理端)
+func (gc *GuildController) AdminDelete(c *gin.Context) {
+	var guild models.Guild
+	if err := database.Tenant(c).First(&guild, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "公会不存在")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	tx.Where("guild_id = ?", guild.ID).Delete(&models.GuildMember{})
+	if err := tx.Delete(&guild).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "解散失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "解散成功", nil)
+}
+
+// ===== 用户端接口 =====
+
+// List 公会列表 (H5端)
+func (gc *GuildController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	name := c.Query("name")
+
+	var guilds []models.Guild
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Guild{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	query.Count(&total)
+	query.Preload("Leader", userBriefPreload).Order("member_count desc, id").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&guilds)
+
+	utils.PageSuccess(c, guilds, total, page, pageSize)
+}
+
+// Create 创建公会
+func (gc *GuildController) Create(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req GuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateGuild(&req, 1); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	guild := models.Guild{
+		Name:        req.Name,
+		Description: req.Description,
+		Icon:        req.Icon,
+		IsOpen:      req.IsOpen,
+		MemberLimit: req.MemberLimit,
+		GoalTarget:  req.GoalTarget,
+		GoalGold:    req.GoalGold,
+		GoalExp:     req.GoalExp,
+	}
+
+	tx := database.Tenant(c).Begin()
+	if err := services.CreateGuild(tx, userID, &guild); err != nil {
+		tx.Rollback()
+		utils.Fail(c, guildError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "创建成功", guild)
+}
+
+// Mine 我的公会: 资料、成员与本周目标
+func (gc *GuildController) Mine(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	member, err := services.MembershipOf(database.Tenant(c), userID)
+	if err != nil {
+		utils.Success(c, nil)
+		return
+	}
+
+	var guild models.Guild
+	database.Tenant(c).Preload("Leader", userBriefPreload).First(&guild, member.GuildID)
+
+	var members []models.GuildMember
+	database.Tenant(c).Preload("User", userBriefPreload).
+		Where("guild_id = ?", guild.ID).
+		Order("FIELD(role, 'leader', 'officer', 'member'), joined_at").
+		Find(&members)
+
+	utils.Success(c, gin.H{
+		"guild":   guild,
+		"role":    member.Role,
+		"members": members,
+		"week":    services.CurrentGuildWeek(database.Tenant(c), &guild),
+	})
+}
+
+// Update 修改公会资料与周目标 (会长/官员), 周目标修改从下周生效
+func (gc *GuildController) Update(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	member, err := services.MembershipOf(database.Tenant(c), userID)
+	if err != nil {
+		utils.Fail(c, guildError(err))
+		return
+	}
+	if !services.CanManageGuild(member) {
+		utils.Fail(c, services.ErrGuildPermission.Error())
+		return
+	}
+
+	var req GuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	var guild models.Guild
+	if err := database.Tenant(c).First(&guild, member.GuildID).Error; err != nil {
+		utils.Fail(c, services.ErrGuildNotFound.Error())
+		return
+	}
+	if msg := validateGuild(&req, guild.MemberCount); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	var count int64
+	database.Tenant(c).Model(&models.Guild{}).Unscoped().
+		Where("name = ? AND id <> ?", req.Name, member.GuildID).Count(&count)
+	if count > 0 {
+		utils.Fail(c, services.ErrGuildNameExists.Error())
+		return
+	}
+
+	database.Tenant(c).Model(&models.Guild{}).Where("id = ?", member.GuildID).Updates(map[string]interface{}{
+		"name":         req.Name,
+		"description":  req.Description,
+		"icon":         req.Icon,
+		"is_open":      req.IsOpen,
+		"member_limit": req.MemberLimit,
+		"goal_target":  req.GoalTarget,
+		"goal_gold":    req.GoalGold,
+		"goal_exp":     req.GoalExp,
+	})
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// Join 加入公会
+func (gc *GuildController) Join(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	guildID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.JoinGuild(tx, userID, uint(guildID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, guildError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "加入成功", nil)
+}
+
+// Leave 退出公会
+func (gc *GuildController) Leave(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.LeaveGuild(tx, userID); err != nil {
+		tx.Rollback()
+		utils.Fail(c, guildError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已退出公会", nil)
+}
+
+// SetRole 调整成员角色 (会长)
+func (gc *GuildController) SetRole(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	targetID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req GuildRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	if err := services.SetMemberRole(tx, userID, uint(targetID), req.Role); err != nil {
+		tx.Rollback()
+		utils.Fail(c, guildError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "设置成功", nil)
+}
+
+// Kick 移出成员 (会长/官员)
+func (gc *GuildController) Kick(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	targetID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	if err := services.KickMember(tx, userID, uint(targetID)); err != nil {
+		tx.Rollback()
+		utils.Fail(c, guildError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "已移出公会", nil)
+}
+
+// Activities 公会动态: 成员入会后完成的任务
+func (gc *GuildController) Activities(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	member, err := services.MembershipOf(database.Tenant(c), userID)
+	if err != nil {
+		utils.Fail(c, guildError(err))
+		return
+	}
+
+	var activities []models.UserTask
+	var total int64
+
+	query := database.Tenant(c).Model(&models.UserTask{}).
+		Joins("JOIN guild_member ON guild_member.user_id = user_task.user_id").
+		Where("guild_member.guild_id = ? AND user_task.completed_at >= guild_member.joined_at AND user_task.status = ?",
+			member.GuildID, services.TaskApproved)
+
+	query.Count(&total)
+	query.Preload("Task", services.Unscoped).Preload("User", userBriefPreload).
+		Order("user_task.completed_at desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&activities)
+
+	utils.PageSuccess(c, activities, total, page, pageSize)
+}
+
+// Weeks 公会历史周目标
+func (gc *GuildController) Weeks(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	member, err := services.MembershipOf(database.Tenant(c), userID)
+	if err != nil {
+		utils.Fail(c, guildError(err))
+		return
+	}
+
+	var weeks []models.GuildWeek
+	database.Tenant(c).Where("guild_id = ?", member.GuildID).Order("week desc").Limit(12).Find(&weeks)
+	utils.Success(c, gin.H{
+		"current": services.GuildWeekKey(time.Now()),
+		"weeks":   weeks,
+	})
+}
+
+// validateGuild 校验公会参数, members 为公会当前成员数
+func validateGuild(req *GuildRequest, members int) string {
+	if req.MemberLimit == 0 {
+		req.MemberLimit = 30
+	}
+	if req.MemberLimit < 0 || req.MemberLimit > 200 {
+		return "成员上限需在1-200之间"
+	}
+	if req.GoalTarget < 0 || req.GoalGold < 0 || req.GoalExp < 0 {
+		return "周目标和奖励不能为负"
+	}
+	if req.MemberLimit < members {
+		return "成员上限不能低于当前成员数"
+	}
+	if err := services.CheckGuildGoal(req.GoalTarget, req.GoalGold, req.GoalExp, members); err != nil {
+		cfg := config.AppConfig.Guild
+		if errors.Is(err, services.ErrGuildGoalReward) {
+			return fmt.Sprintf("周目标奖励最多 %d 金币、%d 经验", cfg.MaxGoalGold, cfg.MaxGoalExp)
+		}
+		return fmt.Sprintf("设置奖励时周目标至少为 %d", services.MinGuildGoalTarget(members))
+	}
+	return ""
+}
+
+// guildError 转换公会操作失败原因
+func guildError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrGuildJoined), errors.Is(err, services.ErrGuildNotMember),
+		errors.Is(err, services.ErrGuildNotFound), errors.Is(err, services.ErrGuildClosed),
+		errors.Is(err, services.ErrGuildFull), errors.Is(err, services.ErrGuildPermission),
+		errors.Is(err, services.ErrGuildLeaderLeave), errors.Is(err, services.ErrGuildRole),
+		errors.Is(err, services.ErrGuildNameExists), errors.Is(err, services.ErrGuildTargetMember):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
@@ -0,0 +1,489 @@
+// Package controllers 家庭控制器
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"life-rpg/config"
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HouseholdController 家庭控制器, 供监护人管理家庭成员、专属任务和奖励
+type HouseholdController struct{}
+
+// HouseholdRequest 创建/修改家庭请求
+type HouseholdRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// ChildRequest 创建孩子账号请求
+type ChildRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=6"`
+	Nickname string `json:"nickname"`
+}
+
+// HouseholdTaskRequest 创建/更新家庭任务请求, 家庭任务只发放金币和经验
+type HouseholdTaskRequest struct {
+	Title           string `json:"title" binding:"required,max=100"`
+	Description     string `json:"description" binding:"max=500"`
+	GoldReward      int    `json:"goldReward"`
+	ExpReward       int    `json:"expReward"`
+	Type            string `json:"type"`
+	Category        string `json:"category" binding:"max=50"`
+	RequireApproval bool   `json:"requireApproval"`
+	Icon            string `json:"icon" binding:"max=50"`
+	IsActive        *bool  `json:"isActive"`
+	Sort            int    `json:"sort"`
+}
+
+// currentHousehold 当前监护人的家庭, 不存在时直接返回错误响应
+func currentHousehold(c *gin.Context) (*models.Household, bool) {
+	household, err := services.GuardianHousehold(database.Tenant(c), middleware.GetCurrentUserID(c))
+	if err != nil {
+		utils.Fail(c, err.Error())
+		return nil, false
+	}
+	return household, true
+}
+
+// Get 我的家庭及成员
+func (hc *HouseholdController) Get(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+	database.Tenant(c).Preload("User", userBriefPreload).Where("household_id = ?", household.ID).
+		Order("id").Find(&household.Members)
+	utils.Success(c, household)
+}
+
+// Create 创建家庭
+func (hc *HouseholdController) Create(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req HouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	household, err := services.CreateHousehold(tx, userID, req.Name)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, householdError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "创建成功", household)
+}
+
+// Update 修改家庭名称
+func (hc *HouseholdController) Update(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var req HouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if err := database.Tenant(c).Model(household).Update("name", req.Name).Error; err != nil {
+		utils.Fail(c, "更新失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "更新成功", household)
+}
+
+// CreateChild 为家庭创建孩子账号
+func (hc *HouseholdController) CreateChild(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var req ChildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.SysUser{}).Where("username = ?", req.Username).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "用户名已存在")
+		return
+	}
+
+	var userRole models.SysRole
+	if err := database.Tenant(c).Where(&models.SysRole{Key: "user"}).First(&userRole).Error; err != nil {
+		utils.Fail(c, "用户角色不存在")
+		return
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	child := models.SysUser{
+		Username: req.Username,
+		Password: string(hashedPassword),
+		Nickname: req.Nickname,
+		RoleID:   userRole.ID,
+		Level:    1,
+		Status:   1,
+	}
+
+	tx := database.Tenant(c).Begin()
+	if err := tx.Create(&child).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "创建失败")
+		return
+	}
+	member, err := services.AddHouseholdMember(tx, household, child.ID, services.HouseholdChild)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, householdError(err))
+		return
+	}
+	tx.Commit()
+
+	member.User = &child
+	utils.SuccessWithMessage(c, "创建成功", member)
+}
+
+// RemoveMember 移出家庭成员
+func (hc *HouseholdController) RemoveMember(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+	userID, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	if err := services.RemoveHouseholdMember(database.Tenant(c), household, uint(userID)); err != nil {
+		utils.Fail(c, householdError(err))
+		return
+	}
+	utils.SuccessWithMessage(c, "移除成功", nil)
+}
+
+// MemberLogs 家庭成员的流水记录
+func (hc *HouseholdController) MemberLogs(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+	userID, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if !services.IsHouseholdMember(database.Tenant(c), household.ID, uint(userID)) {
+		utils.Fail(c, services.ErrHouseholdNotMember.Error())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	logType := c.Query("type")
+	currency := c.Query("currency")
+
+	var logs []models.UserLog
+	var total int64
+
+	query := database.Tenant(c).Model(&models.UserLog{}).Where("user_id = ?", userID)
+	if logType != "" {
+		query = query.Where("type = ?", logType)
+	}
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs)
+
+	utils.PageSuccess(c, logs, total, page, pageSize)
+}
+
+// ===== 家庭任务 =====
+
+// TaskList 家庭任务列表
+func (hc *HouseholdController) TaskList(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var tasks []models.Task
+	database.Tenant(c).Where("household_id = ?", household.ID).
+		Order("sort, id desc").Find(&tasks)
+	utils.Success(c, tasks)
+}
+
+// CreateTask 创建家庭任务
+func (hc *HouseholdController) CreateTask(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var req HouseholdTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateHouseholdTask(&req); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	task := models.Task{HouseholdID: household.ID, IsActive: true}
+	applyHouseholdTask(&task, &req)
+	if err := database.Tenant(c).Create(&task).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "创建成功", task)
+}
+
+// UpdateTask 更新家庭任务
+func (hc *HouseholdController) UpdateTask(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var task models.Task
+	if err := database.Tenant(c).Where("household_id = ?", household.ID).First(&task, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "任务不存在")
+		return
+	}
+
+	var req HouseholdTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateHouseholdTask(&req); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	applyHouseholdTask(&task, &req)
+	database.Tenant(c).Model(&task).Select("title", "description", "gold_reward", "exp_reward", "type",
+		"category", "require_approval", "icon", "is_active", "sort").Updates(&task)
+
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// validateHouseholdTask 校验家庭任务参数, 奖励不得超过配置上限
+func validateHouseholdTask(req *HouseholdTaskRequest) string {
+	cfg := config.AppConfig.Household
+	if req.Type == "" {
+		req.Type = "daily"
+	}
+	if req.Type != "daily" && req.Type != "once" {
+		return "任务类型只能是daily或once"
+	}
+	if req.GoldReward < 0 || req.GoldReward > cfg.MaxTaskGold {
+		return fmt.Sprintf("金币奖励需在0-%d之间", cfg.MaxTaskGold)
+	}
+	if req.ExpReward < 0 || req.ExpReward > cfg.MaxTaskExp {
+		return fmt.Sprintf("经验奖励需在0-%d之间", cfg.MaxTaskExp)
+	}
+	return ""
+}
+
+// applyHouseholdTask 将请求写入家庭任务
+func applyHouseholdTask(task *models.Task, req *HouseholdTaskRequest) {
+	task.Title, task.Description, task.Icon = req.Title, req.Description, req.Icon
+	task.GoldReward, task.ExpReward = req.GoldReward, req.ExpReward
+	task.Type, task.Category, task.Sort = req.Type, req.Category, req.Sort
+	task.RequireApproval = req.RequireApproval
+	if req.IsActive != nil {
+		task.IsActive = *req.IsActive
+	}
+}
+
+// DeleteTask 删除家庭任务
+func (hc *HouseholdController) DeleteTask(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	result := database.Tenant(c).Where("household_id = ?", household.ID).Delete(&models.Task{}, c.Param("id"))
+	if result.Error != nil || result.RowsAffected == 0 {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ===== 家庭奖励 =====
+
+// RewardList 家庭奖励列表
+func (hc *HouseholdController) RewardList(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var rewards []models.Reward
+	database.Tenant(c).Where("household_id = ?", household.ID).Order("sort, id desc").Find(&rewards)
+	utils.Success(c, rewards)
+}
+
+// CreateReward 创建家庭奖励
+func (hc *HouseholdController) CreateReward(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var reward models.Reward
+	if err := c.ShouldBindJSON(&reward); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if reward.Type == services.RewardTypeChest {
+		utils.Fail(c, "家庭奖励不支持宝箱")
+		return
+	}
+	reward.ID, reward.HouseholdID = 0, household.ID
+
+	if err := database.Tenant(c).Create(&reward).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "创建成功", reward)
+}
+
+// UpdateReward 更新家庭奖励
+func (hc *HouseholdController) UpdateReward(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	var reward models.Reward
+	if err := database.Tenant(c).Where("household_id = ?", household.ID).First(&reward, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "奖励不存在")
+		return
+	}
+
+	var updateData models.Reward
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if updateData.Type == services.RewardTypeChest {
+		utils.Fail(c, "家庭奖励不支持宝箱")
+		return
+	}
+	updateData.ID, updateData.HouseholdID = 0, household.ID
+
+	if err := database.Tenant(c).Model(&reward).Updates(updateData).Error; err != nil {
+		utils.Fail(c, "更新失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// DeleteReward 删除家庭奖励
+func (hc *HouseholdController) DeleteReward(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+
+	result := database.Tenant(c).Where("household_id = ?", household.ID).Delete(&models.Reward{}, c.Param("id"))
+	if result.Error != nil || result.RowsAffected == 0 {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ===== 完成审核 =====
+
+// Approvals 家庭任务完成记录, 默认只看待审核
+func (hc *HouseholdController) Approvals(c *gin.Context) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	status := c.DefaultQuery("status", services.TaskPending)
+
+	var records []models.UserTask
+	var total int64
+
+	query := database.Tenant(c).Model(&models.UserTask{}).
+		Joins("JOIN task ON task.id = user_task.task_id").
+		Where("task.household_id = ?", household.ID)
+	if status != "" {
+		query = query.Where("user_task.status = ?", status)
+	}
+
+	query.Count(&total)
+	query.Preload("Task", services.Unscoped).Preload("User", userBriefPreload).
+		Order("user_task.completed_at desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&records)
+
+	utils.PageSuccess(c, records, total, page, pageSize)
+}
+
+// Approve 审核通过并发放奖励
+func (hc *HouseholdController) Approve(c *gin.Context) {
+	hc.review(c, true)
+}
+
+// Reject 驳回, 孩子可重新提交
+func (hc *HouseholdController) Reject(c *gin.Context) {
+	hc.review(c, false)
+}
+
+// review 审核任务完成记录
+func (hc *HouseholdController) review(c *gin.Context, approve bool) {
+	household, ok := currentHousehold(c)
+	if !ok {
+		return
+	}
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	record, payout, err := services.ReviewTask(tx, household, middleware.GetCurrentUserID(c), uint(id), approve)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, householdError(err))
+		return
+	}
+	tx.Commit()
+
+	if !approve {
+		utils.SuccessWithMessage(c, "已驳回", record)
+		return
+	}
+	utils.SuccessWithMessage(c, "已通过", payout)
+}
+
+// householdError 家庭错误转换为提示信息
+func householdError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrHouseholdNone), errors.Is(err, services.ErrHouseholdExists),
+		errors.Is(err, services.ErrHouseholdJoined), errors.Is(err, services.ErrHouseholdNotMember),
+		errors.Is(err, services.ErrHouseholdOwner), errors.Is(err, services.ErrReviewNotFound),
+		errors.Is(err, services.ErrInsufficientGold), errors.Is(err, services.ErrUnknownCurrency):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
@@ -0,0 +1,134 @@
+// Package controllers 排行榜控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardController 排行榜控制器
+type LeaderboardController struct{}
+
+// Refresh 立即重新计算排行榜快照 (管理端)
+func (lc *LeaderboardController) Refresh(c *gin.Context) {
+	if err := services.RefreshLeaderboards(database.DB); err != nil {
+		utils.Fail(c, "排行榜计算失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "排行榜已更新", nil)
+}
+
+// ===== 用户端接口 =====
+
+// Board 排行榜 (H5端)
+// 参数: metric=exp|tasks|streak, period=daily|weekly|monthly|all, scope=global|friends|guild
+// 好友与公会范围按范围内的名次重新排序, 对查看者不可见的用户会被隐去
+func (lc *LeaderboardController) Board(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	metric := c.DefaultQuery("metric", services.MetricExp)
+	period := c.DefaultQuery("period", services.PeriodWeekly)
+	scope := c.DefaultQuery("scope", services.ScopeGlobal)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	if !validMetric(metric) {
+		utils.Fail(c, services.ErrLeaderboardParam.Error())
+		return
+	}
+	_, key, err := services.PeriodWindow(period, time.Now())
+	if err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+	userIDs, err := services.ScopeUserIDs(database.Tenant(c), userID, scope)
+	if err != nil {
+		if errors.Is(err, services.ErrGuildNotMember) || errors.Is(err, services.ErrLeaderboardParam) {
+			utils.Fail(c, err.Error())
+			return
+		}
+		utils.Fail(c, "获取排行榜失败")
+		return
+	}
+
+	query := database.Tenant(c).Where("metric = ? AND period = ? AND period_key = ?", metric, period, key)
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	} else {
+		// 全站范围限定为当前空间的用户
+		query = query.Where("user_id IN (?)", database.Tenant(c).Model(&models.SysUser{}).Select("id"))
+	}
+
+	var entries []models.LeaderboardEntry
+	query.Preload("User", userBriefPreload).Order("`rank`").Limit(limit * 2).Find(&entries)
+
+	list := make([]gin.H, 0, limit)
+	var mine gin.H
+	for _, entry := range entries {
+		if entry.User == nil || !canSeeEntry(userID, &entry, metric) {
+			continue
+		}
+		if len(list) >= limit {
+			break
+		}
+		item := gin.H{
+			"rank":       len(list) + 1,
+			"globalRank": entry.Rank,
+			"value":      entry.Value,
+			"user":       entry.User,
+		}
+		list = append(list, item)
+		if entry.UserID == userID {
+			mine = item
+		}
+	}
+
+	// 自己未进入榜单展示范围时单独返回全站名次
+	if mine == nil {
+		var own models.LeaderboardEntry
+		if err := database.Tenant(c).Where("metric = ? AND period = ? AND period_key = ? AND user_id = ?",
+			metric, period, key, userID).First(&own).Error; err == nil {
+			mine = gin.H{"rank": nil, "globalRank": own.Rank, "value": own.Value}
+		}
+	}
+
+	var updatedAt *time.Time
+	if len(entries) > 0 {
+		updatedAt = &entries[0].UpdatedAt
+	}
+	utils.Success(c, gin.H{
+		"metric":    metric,
+		"period":    period,
+		"periodKey": key,
+		"scope":     scope,
+		"list":      list,
+		"mine":      mine,
+		"updatedAt": updatedAt,
+	})
+}
+
+// canSeeEntry 按被排名用户的隐私设置判断查看者能否看到该条目
+func canSeeEntry(viewerID uint, entry *models.LeaderboardEntry, metric string) bool {
+	privacy := services.GetPrivacy(database.DB, entry.UserID)
+	return services.CanView(database.DB, viewerID, entry.UserID, services.LeaderboardVisibility(privacy, metric))
+}
+
+// validMetric 校验排行榜指标
+func validMetric(metric string) bool {
+	for _, m := range services.LeaderboardMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Package controllers 账务对账控制器
+package controllers
+
+import (
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerController 账务控制器
+type LedgerController struct{}
+
+// ReconcileRequest 对账请求
+type ReconcileRequest struct {
+	UserID uint `json:"userId"` // 0表示全部用户
+	Fix    bool `json:"fix"`
+}
+
+// Reconcile 对账检查 (只读)
+// @Summary 重放流水并比对用户余额
+// @Tags 账务管理
+// @Produce json
+// @Param userId query int false "用户ID, 不传则检查全部用户"
+// @Success 200 {object} utils.Response
+// @Router /api/ledger/reconcile [get]
+func (lc *LedgerController) Reconcile(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Query("userId"), 10, 32)
+	lc.reconcile(c, ReconcileRequest{UserID: uint(userID)})
+}
+
+// Fix 对账并写入修正流水
+// @Summary 对账并为偏差写入修正流水
+// @Tags 账务管理
+// @Accept json
+// @Produce json
+// @Param data body ReconcileRequest true "对账参数"
+// @Success 200 {object} utils.Response
+// @Router /api/ledger/reconcile [post]
+func (lc *LedgerController) Fix(c *gin.Context) {
+	var req ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	lc.reconcile(c, req)
+}
+
+// reconcile 执行对账并返回报告
+func (lc *LedgerController) reconcile(c *gin.Context, req ReconcileRequest) {
+	if req.UserID != 0 {
+		report, err := services.ReconcileUser(database.Tenant(c), req.UserID, req.Fix)
+		if err != nil {
+			utils.Fail(c, "对账失败")
+			return
+		}
+		utils.Success(c, []*services.ReconcileReport{report})
+		return
+	}
+
+	reports, err := services.ReconcileAll(database.Tenant(c), req.Fix)
+	if err != nil {
+		utils.Fail(c, "对账失败")
+		return
+	}
+	utils.Success(c, reports)
+}
@@ -0,0 +1,159 @@
+// Package controllers 邮件控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MailController 邮件控制器
+type MailController struct{}
+
+// List 邮件投递记录, 不返回正文 (重置密码邮件中含有令牌)
+func (mc *MailController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	query := database.Tenant(c).Model(&models.EmailMessage{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if template := c.Query("template"); template != "" {
+		query = query.Where("template = ?", template)
+	}
+	if to := c.Query("to"); to != "" {
+		query = query.Where("`to` LIKE ?", "%"+to+"%")
+	}
+	if userID := c.Query("userId"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	var messages []models.EmailMessage
+	query.Count(&total)
+	query.Omit("body").Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&messages)
+
+	utils.PageSuccess(c, messages, total, page, pageSize)
+}
+
+// Retry 重新发送失败的邮件
+func (mc *MailController) Retry(c *gin.Context) {
+	messageID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := services.RetryMail(database.Tenant(c), uint(messageID)); err != nil {
+		if errors.Is(err, services.ErrMailNotFound) {
+			utils.Fail(c, err.Error())
+		} else {
+			utils.Fail(c, "操作失败")
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已重新加入发送队列", nil)
+}
+
+// TestMailRequest 测试邮件请求
+type TestMailRequest struct {
+	To string `json:"to" binding:"required"`
+}
+
+// Test 发送测试邮件, 用于检查邮件配置
+func (mc *MailController) Test(c *gin.Context) {
+	var req TestMailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	to, err := services.NormalizeEmail(req.To)
+	if err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	if err := services.SendTestMail(database.Tenant(c), middleware.GetCurrentTenantID(c), to); err != nil {
+		utils.Fail(c, mailError(err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "测试邮件已加入发送队列", nil)
+}
+
+// MailSettingsRequest 邮件设置请求, email 为空表示解绑邮箱, 修改邮箱时需提供当前密码
+type MailSettingsRequest struct {
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	WeeklySummary *bool  `json:"weeklySummary"`
+}
+
+// UpdateSettings 修改我的周报订阅和邮箱, 新邮箱需通过验证邮件确认后生效
+func (mc *MailController) UpdateSettings(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req MailSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var user models.SysUser
+	if err := database.Tenant(c).First(&user, userID).Error; err != nil {
+		utils.Fail(c, "用户不存在")
+		return
+	}
+
+	email := ""
+	if req.Email != "" {
+		normalized, err := services.NormalizeEmail(req.Email)
+		if err != nil {
+			utils.Fail(c, err.Error())
+			return
+		}
+		email = normalized
+	}
+	changed := email != user.Email
+	if changed && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		utils.Fail(c, "当前密码错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	if req.WeeklySummary != nil {
+		tx.Model(&user).Update("weekly_summary", *req.WeeklySummary)
+	}
+	message := "设置已保存"
+	if changed && email == "" {
+		tx.Model(&user).Update("email", "")
+	} else if changed {
+		if err := services.RequestEmailChange(tx, &user, email); err != nil {
+			tx.Rollback()
+			utils.Fail(c, mailError(err))
+			return
+		}
+		message = "验证邮件已发送至新邮箱, 验证后生效"
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, message, nil)
+}
+
+// mailError 邮件错误转为提示
+func mailError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrMailDisabled),
+		errors.Is(err, services.ErrMailTemplate),
+		errors.Is(err, services.ErrMailNoAddress),
+		errors.Is(err, services.ErrMailAddress):
+		return err.Error()
+	default:
+		return "发送失败"
+	}
+}
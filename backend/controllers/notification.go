@@ -0,0 +1,102 @@
+// Package controllers 站内通知控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationController 通知控制器
+type NotificationController struct{}
+
+// SendNotificationRequest 管理员发送消息请求, userIds 为空时发送给全部用户
+type SendNotificationRequest struct {
+	UserIDs []uint `json:"userIds"`
+	Title   string `json:"title" binding:"required,max=100"`
+	Body    string `json:"body" binding:"max=500"`
+	Link    string `json:"link" binding:"max=255"`
+}
+
+// Send 发送管理员消息 (管理端)
+func (nc *NotificationController) Send(c *gin.Context) {
+	var req SendNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	count, err := services.SendAdminMessage(tx, middleware.GetCurrentUserID(c), req.UserIDs, req.Title, req.Body, req.Link)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, "发送失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "发送成功", gin.H{"count": count})
+}
+
+// ===== 用户端接口 =====
+
+// List 我的通知 (H5端), unread=1 时只返回未读通知
+func (nc *NotificationController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	var notifications []models.Notification
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if c.Query("unread") == "1" {
+		query = query.Where("read_at IS NULL")
+	}
+	query.Count(&total)
+	query.Preload("Sender", userBriefPreload).Order("id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&notifications)
+
+	utils.PageSuccess(c, notifications, total, page, pageSize)
+}
+
+// UnreadCount 未读通知数
+func (nc *NotificationController) UnreadCount(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	utils.Success(c, gin.H{"count": services.UnreadCount(database.Tenant(c), userID)})
+}
+
+// Read 标记通知已读
+func (nc *NotificationController) Read(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := services.MarkRead(database.Tenant(c), userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			utils.Fail(c, err.Error())
+			return
+		}
+		utils.Fail(c, "操作失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "已读", nil)
+}
+
+// ReadAll 全部标记已读
+func (nc *NotificationController) ReadAll(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	count, err := services.MarkAllRead(database.Tenant(c), userID)
+	if err != nil {
+		utils.Fail(c, "操作失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "已全部标记为已读", gin.H{"count": count})
+}
@@ -0,0 +1,219 @@
+// Package controllers 促销与优惠券控制器
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"life-rpg/database"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionController 促销控制器
+type PromotionController struct{}
+
+// SaleList 促销活动列表
+func (pc *PromotionController) SaleList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	var sales []models.SaleEvent
+	var total int64
+
+	query := database.Tenant(c).Model(&models.SaleEvent{})
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&sales)
+
+	utils.PageSuccess(c, sales, total, page, pageSize)
+}
+
+// CreateSale 创建促销活动
+func (pc *PromotionController) CreateSale(c *gin.Context) {
+	var sale models.SaleEvent
+	if err := c.ShouldBindJSON(&sale); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateSale(&sale); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	if err := database.Tenant(c).Create(&sale).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", sale)
+}
+
+// UpdateSale 更新促销活动
+func (pc *PromotionController) UpdateSale(c *gin.Context) {
+	id := c.Param("id")
+	var sale models.SaleEvent
+	if err := database.Tenant(c).First(&sale, id).Error; err != nil {
+		utils.Fail(c, "促销活动不存在")
+		return
+	}
+
+	var updateData models.SaleEvent
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if msg := validateSale(&updateData); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	database.Tenant(c).Model(&sale).Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// DeleteSale 删除促销活动
+func (pc *PromotionController) DeleteSale(c *gin.Context) {
+	id := c.Param("id")
+	if err := database.Tenant(c).Delete(&models.SaleEvent{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// validateSale 校验促销活动参数
+func validateSale(sale *models.SaleEvent) string {
+	if sale.Percent <= 0 || sale.Percent > 100 {
+		return "折扣比例需在1-100之间"
+	}
+	if !sale.EndAt.After(sale.StartAt) {
+		return "结束时间需晚于开始时间"
+	}
+	return ""
+}
+
+// CouponList 优惠券列表
+func (pc *PromotionController) CouponList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	code := c.Query("code")
+
+	var coupons []models.Coupon
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Coupon{})
+	if code != "" {
+		query = query.Where("code LIKE ?", "%"+code+"%")
+	}
+
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&coupons)
+
+	utils.PageSuccess(c, coupons, total, page, pageSize)
+}
+
+// CreateCoupon 创建优惠券
+func (pc *PromotionController) CreateCoupon(c *gin.Context) {
+	var coupon models.Coupon
+	if err := c.ShouldBindJSON(&coupon); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	coupon.Code = strings.TrimSpace(coupon.Code)
+	coupon.UsedCount = 0
+	if msg := validateCoupon(&coupon); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	var count int64
+	database.Tenant(c).Model(&models.Coupon{}).Unscoped().Where("code = ?", coupon.Code).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "优惠券码已存在")
+		return
+	}
+
+	if err := database.Tenant(c).Create(&coupon).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", coupon)
+}
+
+// UpdateCoupon 更新优惠券 (券码和已用次数不可修改)
+func (pc *PromotionController) UpdateCoupon(c *gin.Context) {
+	id := c.Param("id")
+	var coupon models.Coupon
+	if err := database.Tenant(c).First(&coupon, id).Error; err != nil {
+		utils.Fail(c, "优惠券不存在")
+		return
+	}
+
+	var updateData models.Coupon
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	updateData.Code = coupon.Code
+	if msg := validateCoupon(&updateData); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	database.Tenant(c).Model(&coupon).Omit("code", "used_count").Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// DeleteCoupon 删除优惠券
+func (pc *PromotionController) DeleteCoupon(c *gin.Context) {
+	id := c.Param("id")
+	if err := database.Tenant(c).Delete(&models.Coupon{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// CouponRedemptions 优惠券使用记录
+func (pc *PromotionController) CouponRedemptions(c *gin.Context) {
+	// 使用记录没有空间字段, 先确认优惠券属于当前空间
+	var coupon models.Coupon
+	if err := database.Tenant(c).First(&coupon, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "优惠券不存在")
+		return
+	}
+
+	var redemptions []models.CouponRedemption
+	database.Tenant(c).Where("coupon_id = ?", coupon.ID).Order("id desc").Find(&redemptions)
+	utils.Success(c, redemptions)
+}
+
+// validateCoupon 校验优惠券参数
+func validateCoupon(coupon *models.Coupon) string {
+	if coupon.Code == "" {
+		return "优惠券码不能为空"
+	}
+	switch coupon.Type {
+	case services.CouponPercent:
+		if coupon.Value <= 0 || coupon.Value > 100 {
+			return "折扣比例需在1-100之间"
+		}
+	case services.CouponAmount:
+		if coupon.Value <= 0 {
+			return "减免数量必须大于0"
+		}
+	default:
+		return "不支持的优惠券类型"
+	}
+	if coupon.MaxUses < 0 || coupon.PerUserLimit < 0 {
+		return "使用次数不能为负数"
+	}
+	if coupon.StartAt != nil && coupon.EndAt != nil && !coupon.EndAt.After(*coupon.StartAt) {
+		return "结束时间需晚于开始时间"
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// Package controllers 任务提醒控制器
+package controllers
+
+import (
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReminderController 任务提醒控制器
+type ReminderController struct{}
+
+// ReminderRequest 创建/更新提醒请求, timezone 为空时使用默认时区
+type ReminderRequest struct {
+	TaskID   uint   `json:"taskId"`
+	RemindAt string `json:"remindAt" binding:"required"`
+	Weekdays string `json:"weekdays"`
+	Timezone string `json:"timezone"`
+	Channels string `json:"channels"`
+	IsActive *bool  `json:"isActive"`
+}
+
+// List 我的任务提醒
+func (rc *ReminderController) List(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var reminders []models.TaskReminder
+	database.Tenant(c).Preload("Task", services.Unscoped).
+		Where("user_id = ?", userID).
+		Order("remind_at, id").
+		Find(&reminders)
+
+	utils.Success(c, reminders)
+}
+
+// Channels 可选的通知渠道
+func (rc *ReminderController) Channels(c *gin.Context) {
+	utils.Success(c, services.ChannelNames())
+}
+
+// Create 创建任务提醒
+func (rc *ReminderController) Create(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var task models.Task
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		Where("is_active = ?", true).First(&task, req.TaskID).Error; err != nil {
+		utils.Fail(c, "任务不存在")
+		return
+	}
+	if err := services.CheckReminderLimit(database.Tenant(c), userID); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	reminder := models.TaskReminder{
+		UserID:   userID,
+		TaskID:   task.ID,
+		RemindAt: req.RemindAt,
+		Weekdays: req.Weekdays,
+		Timezone: req.Timezone,
+		Channels: req.Channels,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		reminder.IsActive = *req.IsActive
+	}
+	if err := services.NormalizeReminder(&reminder); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+	if err := database.Tenant(c).Create(&reminder).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	reminder.Task = &task
+	utils.SuccessWithMessage(c, "提醒已设置", reminder)
+}
+
+// Update 修改任务提醒, 修改后当天未发送的提醒会按新时间重新检查
+func (rc *ReminderController) Update(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	reminderID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var reminder models.TaskReminder
+	if err := database.Tenant(c).Where("id = ? AND user_id = ?", reminderID, userID).
+		First(&reminder).Error; err != nil {
+		utils.Fail(c, services.ErrReminderNotFound.Error())
+		return
+	}
+
+	reminder.RemindAt = req.RemindAt
+	reminder.Weekdays = req.Weekdays
+	reminder.Timezone = req.Timezone
+	reminder.Channels = req.Channels
+	if req.IsActive != nil {
+		reminder.IsActive = *req.IsActive
+	}
+	if err := services.NormalizeReminder(&reminder); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	database.Tenant(c).Model(&reminder).Select("remind_at", "weekdays", "timezone", "channels", "is_active").
+		Updates(&reminder)
+	utils.SuccessWithMessage(c, "更新成功", reminder)
+}
+
+// Delete 删除任务提醒
+func (rc *ReminderController) Delete(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	reminderID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	result := database.Tenant(c).Where("id = ? AND user_id = ?", reminderID, userID).
+		Delete(&models.TaskReminder{})
+	if result.RowsAffected == 0 {
+		utils.Fail(c, services.ErrReminderNotFound.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
@@ -2,14 +2,18 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"strconv"
 
 	"life-rpg/database"
 	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/services"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // RewardController 奖励控制器
@@ -23,7 +27,7 @@ func (rc *RewardController) List(c *gin.Context) {
 	var rewards []models.Reward
 	var total int64
 
-	query := database.DB.Model(&models.Reward{})
+	query := database.Tenant(c).Model(&models.Reward{})
 	query.Count(&total)
 	query.Order("sort, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&rewards)
 
@@ -38,7 +42,7 @@ func (rc *RewardController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&reward).Error; err != nil {
+	if err := database.Tenant(c).Create(&reward).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
@@ -50,7 +54,7 @@ func (rc *RewardController) Create(c *gin.Context) {
 func (rc *RewardController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var reward models.Reward
-	if err := database.DB.First(&reward, id).Error; err != nil {
+	if err := database.Tenant(c).First(&reward, id).Error; err != nil {
 		utils.Fail(c, "奖励不存在")
 		return
 	}
@@ -61,14 +65,14 @@ func (rc *RewardController) Update(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&reward).Updates(updateData)
+	database.Tenant(c).Model(&reward).Updates(updateData)
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
 // Delete 删除奖励
 func (rc *RewardController) Delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := database.DB.Delete(&models.Reward{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.Reward{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
@@ -79,9 +83,55 @@ func (rc *RewardController) Delete(c *gin.Context) {
 
 // UserRewardList 用户奖励列表 (H5端)
 func (rc *RewardController) UserRewardList(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	// 全站奖励和所在家庭的奖励
 	var rewards []models.Reward
-	database.DB.Where("is_active = ?", true).Order("sort").Find(&rewards)
-	utils.Success(c, rewards)
+	database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		Where("is_active = ?", true).Order("sort").Find(&rewards)
+
+	// 附带当前促销价
+	type RewardWithSale struct {
+		models.Reward
+		SalePrice   int    `json:"salePrice"`
+		SalePercent int    `json:"salePercent"`
+		SaleName    string `json:"saleName,omitempty"`
+	}
+
+	sales := services.ActiveSales(database.Tenant(c))
+	result := make([]RewardWithSale, 0, len(rewards))
+	for i := range rewards {
+		item := RewardWithSale{Reward: rewards[i], SalePrice: rewards[i].Cost}
+		if sale := services.BestSale(sales, &rewards[i]); sale != nil {
+			item.SalePrice = rewards[i].Cost - rewards[i].Cost*sale.Percent/100
+			item.SalePercent, item.SaleName = sale.Percent, sale.Name
+		}
+		result = append(result, item)
+	}
+	utils.Success(c, result)
+}
+
+// PurchaseRequest 兑换请求
+type PurchaseRequest struct {
+	CouponCode string `json:"couponCode"`
+}
+
+// Quote 兑换价格预览 (H5端)
+func (rc *RewardController) Quote(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var reward models.Reward
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).First(&reward, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "奖励不存在")
+		return
+	}
+
+	breakdown, _, err := services.QuotePrice(database.Tenant(c), userID, &reward, c.Query("couponCode"))
+	if err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+	utils.Success(c, breakdown)
 }
 
 // Purchase 购买奖励
@@ -89,9 +139,16 @@ func (rc *RewardController) Purchase(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 	rewardID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 
+	// 请求体可选, 用于传入优惠券码
+	var req PurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
 	// 获取奖励信息
 	var reward models.Reward
-	if err := database.DB.First(&reward, rewardID).Error; err != nil {
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).First(&reward, rewardID).Error; err != nil {
 		utils.Fail(c, "奖励不存在")
 		return
 	}
@@ -107,45 +164,164 @@ func (rc *RewardController) Purchase(c *gin.Context) {
 		return
 	}
 
-	// 获取用户信息
-	var user models.SysUser
-	database.DB.First(&user, userID)
-
-	// 检查金币是否足够
-	if user.Gold < reward.Cost {
-		utils.Fail(c, "金币不足")
+	// 计算促销和优惠券后的价格
+	breakdown, coupon, err := services.QuotePrice(database.Tenant(c), userID, &reward, req.CouponCode)
+	if err != nil {
+		utils.Fail(c, err.Error())
 		return
 	}
+	description := "兑换奖励: " + reward.Title
+	if detail := breakdown.Describe(); detail != "" {
+		description += " (" + detail + ")"
+	}
 
 	// 开始事务
-	tx := database.DB.Begin()
+	tx := database.Tenant(c).Begin()
 
-	// 扣除金币
-	newGold := user.Gold - reward.Cost
-	tx.Model(&user).Update("gold", newGold)
+	// 扣减库存并按计价币种扣款
+	newBalance, logID, err := services.BuyReward(tx, userID, &reward, breakdown.Final, services.PurchaseMeta(&reward, description))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, purchaseError(err))
+		return
+	}
 
-	// 减少库存
-	if reward.Stock > 0 {
-		tx.Model(&reward).Update("stock", reward.Stock-1)
+	// 使用优惠券, 关联本次扣款流水
+	if coupon != nil {
+		priceBeforeCoupon := breakdown.Final + breakdown.CouponDiscount
+		if err := services.RedeemCoupon(tx, coupon.ID, userID, &reward, priceBeforeCoupon, breakdown.CouponDiscount, logID); err != nil {
+			tx.Rollback()
+			utils.Fail(c, purchaseError(err))
+			return
+		}
 	}
 
-	// 记录流水
-	log := models.UserLog{
-		UserID:      userID,
-		Type:        "gold_out",
-		Amount:      reward.Cost,
-		Balance:     newGold,
-		Description: "兑换奖励: " + reward.Title,
-		RefType:     "reward",
-		RefID:       uint(rewardID),
+	// 宝箱购买后立即开启
+	var chestOpen *models.ChestOpen
+	if reward.Type == services.RewardTypeChest {
+		if chestOpen, err = services.OpenChest(tx, userID, &reward); err != nil {
+			tx.Rollback()
+			utils.Fail(c, purchaseError(err))
+			return
+		}
 	}
-	tx.Create(&log)
 
 	tx.Commit()
 
+	// newGold 保持为金币余额, 兼容旧版H5
+	newGold := newBalance
+	if reward.Currency != services.CurrencyGold {
+		var user models.SysUser
+		database.Tenant(c).Select("gold").First(&user, userID)
+		newGold = user.Gold
+	}
+
 	utils.Success(c, gin.H{
-		"cost":    reward.Cost,
-		"newGold": newGold,
-		"reward":  reward.Title,
+		"cost":       breakdown.Final,
+		"price":      breakdown,
+		"currency":   reward.Currency,
+		"newBalance": newBalance,
+		"newGold":    newGold,
+		"reward":     reward.Title,
+		"chestOpen":  chestOpen,
+	})
+}
+
+// purchaseError 转换兑换失败原因
+func purchaseError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrInsufficientGold), errors.Is(err, services.ErrInsufficientBalance),
+		errors.Is(err, services.ErrOutOfStock), errors.Is(err, services.ErrChestEmpty),
+		errors.Is(err, services.ErrCouponInvalid), errors.Is(err, services.ErrCouponUsedUp),
+		errors.Is(err, services.ErrCouponUserUsed), errors.Is(err, services.ErrCouponMinCost),
+		errors.Is(err, services.ErrCouponCategory):
+		return err.Error()
+	default:
+		return "兑换失败"
+	}
+}
+
+// RefundRequest 退款请求
+type RefundRequest struct {
+	Reason string `json:"reason" binding:"required,max=100"`
+}
+
+// Refund 退还奖励兑换 (管理端)
+// 根据兑换流水退回金币、恢复库存并撤销优惠券使用，每条兑换流水只能退款一次
+// 宝箱兑换已发放掉落, 不支持退款
+func (rc *RewardController) Refund(c *gin.Context) {
+	operatorID := middleware.GetCurrentUserID(c)
+	logID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "请填写退款原因")
+		return
+	}
+
+	var purchaseLog models.UserLog
+	if err := database.Tenant(c).First(&purchaseLog, logID).Error; err != nil {
+		utils.Fail(c, "流水不存在")
+		return
+	}
+	if purchaseLog.RefType != "reward" || purchaseLog.Type != services.LogTypeOut(purchaseLog.Currency) {
+		utils.Fail(c, "仅支持退还奖励兑换流水")
+		return
+	}
+	// 宝箱兑换后已开箱发放掉落, 退款会让用户白拿掉落后重新开箱
+	var reward models.Reward
+	if err := database.Tenant(c).Unscoped().Select("id", "type").First(&reward, purchaseLog.RefID).Error; err == nil &&
+		reward.Type == services.RewardTypeChest {
+		utils.Fail(c, "宝箱兑换不支持退款")
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+
+	// 锁定用户后再检查，防止并发重复退款
+	if _, err := services.LockUser(tx, purchaseLog.UserID); err != nil {
+		tx.Rollback()
+		utils.Fail(c, "用户不存在")
+		return
+	}
+	var refunded int64
+	tx.Model(&models.UserLog{}).
+		Where("ref_type = ? AND ref_id = ?", "refund", purchaseLog.ID).
+		Count(&refunded)
+	if refunded > 0 {
+		tx.Rollback()
+		utils.Fail(c, "该流水已退款")
+		return
+	}
+
+	newBalance, err := services.ChangeCurrency(tx, purchaseLog.UserID, purchaseLog.Currency, purchaseLog.Amount, services.LogMeta{
+		Description: "退款: " + purchaseLog.Description + " (" + req.Reason + ")",
+		RefType:     "refund",
+		RefID:       purchaseLog.ID,
+		OperatorID:  operatorID,
+	})
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, "退款失败")
+		return
+	}
+
+	// 恢复有限库存
+	tx.Model(&models.Reward{}).
+		Where("id = ? AND stock >= 0", purchaseLog.RefID).
+		Update("stock", gorm.Expr("stock + 1"))
+
+	if err := services.RevokeCoupon(tx, purchaseLog.ID); err != nil {
+		tx.Rollback()
+		utils.Fail(c, "退款失败")
+		return
+	}
+
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "退款成功", gin.H{
+		"amount":     purchaseLog.Amount,
+		"currency":   purchaseLog.Currency,
+		"newBalance": newBalance,
 	})
 }
@@ -17,7 +17,7 @@ type RoleController struct{}
 // List 角色列表
 func (rc *RoleController) List(c *gin.Context) {
 	var roles []models.SysRole
-	database.DB.Order("sort").Find(&roles)
+	database.Tenant(c).Order("sort").Find(&roles)
 	utils.Success(c, roles)
 }
 
@@ -29,7 +29,7 @@ func (rc *RoleController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&role).Error; err != nil {
+	if err := database.Tenant(c).Create(&role).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
@@ -41,7 +41,7 @@ func (rc *RoleController) Create(c *gin.Context) {
 func (rc *RoleController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var role models.SysRole
-	if err := database.DB.First(&role, id).Error; err != nil {
+	if err := database.Tenant(c).First(&role, id).Error; err != nil {
 		utils.Fail(c, "角色不存在")
 		return
 	}
@@ -52,7 +52,7 @@ func (rc *RoleController) Update(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&role).Updates(updateData)
+	database.Tenant(c).Model(&role).Updates(updateData)
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
@@ -66,7 +66,7 @@ func (rc *RoleController) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&models.SysRole{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.SysRole{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
@@ -91,11 +91,11 @@ func (rc *RoleController) AssignMenus(c *gin.Context) {
 	}
 
 	// 删除原有的角色菜单关联
-	database.DB.Where("role_id = ?", roleID).Delete(&models.RoleMenu{})
+	database.Tenant(c).Where("role_id = ?", roleID).Delete(&models.RoleMenu{})
 
 	// 创建新的关联
 	for _, menuID := range req.MenuIDs {
-		database.DB.Create(&models.RoleMenu{RoleID: uint(roleID), MenuID: menuID})
+		database.Tenant(c).Create(&models.RoleMenu{RoleID: uint(roleID), MenuID: menuID})
 	}
 
 	utils.SuccessWithMessage(c, "分配成功", nil)
@@ -106,7 +106,7 @@ func (rc *RoleController) GetRoleMenus(c *gin.Context) {
 	id := c.Param("id")
 
 	var roleMenus []models.RoleMenu
-	database.DB.Where("role_id = ?", id).Find(&roleMenus)
+	database.Tenant(c).Where("role_id = ?", id).Find(&roleMenus)
 
 	var menuIDs []uint
 	for _, rm := range roleMenus {
@@ -122,7 +122,7 @@ type MenuController struct{}
 // List 菜单树列表
 func (mc *MenuController) List(c *gin.Context) {
 	var menus []models.SysMenu
-	database.DB.Order("sort").Find(&menus)
+	database.Tenant(c).Order("sort").Find(&menus)
 
 	// 构建树形结构
 	tree := buildMenuTree(menus, 0)
@@ -132,7 +132,7 @@ func (mc *MenuController) List(c *gin.Context) {
 // ListAll 所有菜单列表(扁平)
 func (mc *MenuController) ListAll(c *gin.Context) {
 	var menus []models.SysMenu
-	database.DB.Order("sort").Find(&menus)
+	database.Tenant(c).Order("sort").Find(&menus)
 	utils.Success(c, menus)
 }
 
@@ -144,7 +144,7 @@ func (mc *MenuController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&menu).Error; err != nil {
+	if err := database.Tenant(c).Create(&menu).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
@@ -156,7 +156,7 @@ func (mc *MenuController) Create(c *gin.Context) {
 func (mc *MenuController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var menu models.SysMenu
-	if err := database.DB.First(&menu, id).Error; err != nil {
+	if err := database.Tenant(c).First(&menu, id).Error; err != nil {
 		utils.Fail(c, "菜单不存在")
 		return
 	}
@@ -167,7 +167,7 @@ func (mc *MenuController) Update(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&menu).Updates(updateData)
+	database.Tenant(c).Model(&menu).Updates(updateData)
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
@@ -177,19 +177,19 @@ func (mc *MenuController) Delete(c *gin.Context) {
 
 	// 检查是否有子菜单
 	var count int64
-	database.DB.Model(&models.SysMenu{}).Where("parent_id = ?", id).Count(&count)
+	database.Tenant(c).Model(&models.SysMenu{}).Where("parent_id = ?", id).Count(&count)
 	if count > 0 {
 		utils.Fail(c, "存在子菜单，无法删除")
 		return
 	}
 
-	if err := database.DB.Delete(&models.SysMenu{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.SysMenu{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
 
 	// 删除关联
-	database.DB.Where("menu_id = ?", id).Delete(&models.RoleMenu{})
+	database.Tenant(c).Where("menu_id = ?", id).Delete(&models.RoleMenu{})
 
 	utils.SuccessWithMessage(c, "删除成功", nil)
 }
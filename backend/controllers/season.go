@@ -0,0 +1,294 @@
+// Package controllers 赛季通行证控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SeasonController 赛季控制器
+type SeasonController struct{}
+
+// SeasonTiersRequest 赛季档位保存请求
+type SeasonTiersRequest struct {
+	Tiers []models.SeasonTier `json:"tiers"`
+}
+
+// List 赛季列表
+func (sc *SeasonController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	var seasons []models.Season
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Season{})
+	query.Count(&total)
+	query.Order("start_at desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&seasons)
+
+	utils.PageSuccess(c, seasons, total, page, pageSize)
+}
+
+// Create 创建赛季
+func (sc *SeasonController) Create(c *gin.Context) {
+	var season models.Season
+	if err := c.ShouldBindJSON(&season); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	season.ArchivedAt = nil
+	season.Tiers = nil
+	if msg := validateSeason(&season, 0); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	if err := database.Tenant(c).Create(&season).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", season)
+}
+
+// Update 更新赛季 (已归档的赛季不可修改)
+func (sc *SeasonController) Update(c *gin.Context) {
+	id := c.Param("id")
+	var season models.Season
+	if err := database.Tenant(c).First(&season, id).Error; err != nil {
+		utils.Fail(c, "赛季不存在")
+		return
+	}
+	if season.ArchivedAt != nil {
+		utils.Fail(c, "赛季已归档, 不可修改")
+		return
+	}
+
+	var updateData models.Season
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	updateData.ArchivedAt = nil
+	updateData.Tiers = nil
+	if msg := validateSeason(&updateData, season.ID); msg != "" {
+		utils.Fail(c, msg)
+		return
+	}
+
+	database.Tenant(c).Model(&season).Updates(updateData)
+	utils.SuccessWithMessage(c, "更新成功", nil)
+}
+
+// Delete 删除赛季 (已有用户进度的赛季不可删除)
+func (sc *SeasonController) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	var count int64
+	database.Tenant(c).Model(&models.UserSeason{}).Where("season_id = ?", id).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "赛季已有用户参与, 不可删除")
+		return
+	}
+
+	if err := database.Tenant(c).Delete(&models.Season{}, id).Error; err != nil {
+		utils.Fail(c, "删除失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// Tiers 赛季档位
+func (sc *SeasonController) Tiers(c *gin.Context) {
+	seasonID := c.Param("id")
+
+	var tiers []models.SeasonTier
+	database.Tenant(c).Where("season_id = ?", seasonID).Order("level, track").Find(&tiers)
+	utils.Success(c, tiers)
+}
+
+// SaveTiers 整体替换赛季档位, 已有用户领取奖励后不可再修改, 避免新档位被重复领取
+func (sc *SeasonController) SaveTiers(c *gin.Context) {
+	var season models.Season
+	if err := database.Tenant(c).First(&season, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "赛季不存在")
+		return
+	}
+	if season.ArchivedAt != nil {
+		utils.Fail(c, "赛季已归档, 不可修改")
+		return
+	}
+	var claimed int64
+	database.Tenant(c).Model(&models.SeasonClaim{}).Where("season_id = ?", season.ID).Count(&claimed)
+	if claimed > 0 {
+		utils.Fail(c, "已有用户领取档位奖励, 不可修改档位")
+		return
+	}
+
+	var req SeasonTiersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	for _, tier := range req.Tiers {
+		if tier.Level <= 0 || tier.Points < 0 {
+			utils.Fail(c, "档位等级必须大于0且积分不能为负")
+			return
+		}
+		if tier.Track != services.TrackFree && tier.Track != services.TrackPremium {
+			utils.Fail(c, "奖励轨道只能是free或premium")
+			return
+		}
+	}
+
+	tx := database.Tenant(c).Begin()
+	tx.Where("season_id = ?", season.ID).Delete(&models.SeasonTier{})
+	for _, tier := range req.Tiers {
+		tier.ID = 0
+		tier.SeasonID = season.ID
+		if err := tx.Create(&tier).Error; err != nil {
+			tx.Rollback()
+			utils.Fail(c, "保存失败")
+			return
+		}
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "保存成功", nil)
+}
+
+// Progress 赛季用户进度 (管理端)
+func (sc *SeasonController) Progress(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	var progresses []models.UserSeason
+	var total int64
+
+	query := database.Tenant(c).Model(&models.UserSeason{}).Where("season_id = ?", c.Param("id")).
+		Where("user_id IN (?)", database.Tenant(c).Model(&models.SysUser{}).Select("id"))
+	query.Count(&total)
+	query.Order("points desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&progresses)
+
+	utils.PageSuccess(c, progresses, total, page, pageSize)
+}
+
+// validateSeason 校验赛季参数
+func validateSeason(season *models.Season, id uint) string {
+	if season.Name == "" {
+		return "赛季名称不能为空"
+	}
+	if !season.EndAt.After(season.StartAt) {
+		return "结束时间需晚于开始时间"
+	}
+	if season.PointPercent < 0 || season.PremiumPrice < 0 {
+		return "积分比例和通行证价格不能为负"
+	}
+	if err := services.CheckSeasonOverlap(database.DB, season.StartAt, season.EndAt, id); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// ===== 用户端接口 =====
+
+// Current 当前赛季、档位与我的进度 (H5端)
+func (sc *SeasonController) Current(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	season, err := services.ActiveSeason(database.Tenant(c))
+	if err != nil {
+		utils.Success(c, nil)
+		return
+	}
+
+	var tiers []models.SeasonTier
+	database.Tenant(c).Where("season_id = ?", season.ID).Order("level, track").Find(&tiers)
+
+	var progress models.UserSeason
+	database.Tenant(c).Where("user_id = ? AND season_id = ?", userID, season.ID).First(&progress)
+
+	var claimed []uint
+	database.Tenant(c).Model(&models.SeasonClaim{}).
+		Where("user_id = ? AND season_id = ?", userID, season.ID).
+		Pluck("tier_id", &claimed)
+
+	utils.Success(c, gin.H{
+		"season":  season,
+		"tiers":   tiers,
+		"points":  progress.Points,
+		"premium": progress.Premium,
+		"tier":    services.ReachedTier(tiers, progress.Points),
+		"claimed": claimed,
+	})
+}
+
+// UnlockPremium 解锁高级通行证
+func (sc *SeasonController) UnlockPremium(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	tx := database.Tenant(c).Begin()
+	progress, err := services.UnlockPremium(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, seasonError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "解锁成功", progress)
+}
+
+// Claim 领取档位奖励
+func (sc *SeasonController) Claim(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	tierID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	tx := database.Tenant(c).Begin()
+	tier, err := services.ClaimTier(tx, userID, uint(tierID))
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, seasonError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "领取成功", tier)
+}
+
+// History 往期赛季记录 (H5端)
+func (sc *SeasonController) History(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var progresses []models.UserSeason
+	database.Tenant(c).Preload("Season", services.Unscoped).
+		Where("user_id = ? AND archived = ?", userID, true).
+		Order("id desc").
+		Find(&progresses)
+	utils.Success(c, progresses)
+}
+
+// seasonError 转换赛季操作失败原因
+func seasonError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "档位不存在"
+	case errors.Is(err, services.ErrNoActiveSeason), errors.Is(err, services.ErrSeasonArchived),
+		errors.Is(err, services.ErrSeasonPremiumOwned), errors.Is(err, services.ErrSeasonPremiumOnly),
+		errors.Is(err, services.ErrSeasonTierLocked), errors.Is(err, services.ErrSeasonTierClaimed),
+		errors.Is(err, services.ErrInsufficientGold), errors.Is(err, services.ErrInsufficientBalance),
+		errors.Is(err, services.ErrUnknownCurrency):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
@@ -2,12 +2,14 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
 	"life-rpg/database"
 	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/services"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -25,13 +27,13 @@ func (tc *TaskController) List(c *gin.Context) {
 	var tasks []models.Task
 	var total int64
 
-	query := database.DB.Model(&models.Task{})
+	query := database.Tenant(c).Model(&models.Task{})
 	if taskType != "" {
 		query = query.Where("type = ?", taskType)
 	}
 
 	query.Count(&total)
-	query.Order("sort, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&tasks)
+	query.Preload("CurrencyRewards").Order("sort, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&tasks)
 
 	utils.PageSuccess(c, tasks, total, page, pageSize)
 }
@@ -44,7 +46,7 @@ func (tc *TaskController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&task).Error; err != nil {
+	if err := database.Tenant(c).Create(&task).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
@@ -56,7 +58,7 @@ func (tc *TaskController) Create(c *gin.Context) {
 func (tc *TaskController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var task models.Task
-	if err := database.DB.First(&task, id).Error; err != nil {
+	if err := database.Tenant(c).First(&task, id).Error; err != nil {
 		utils.Fail(c, "任务不存在")
 		return
 	}
@@ -67,14 +69,25 @@ func (tc *TaskController) Update(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&task).Updates(updateData)
+	tx := database.Tenant(c).Begin()
+	tx.Model(&task).Omit("CurrencyRewards").Updates(updateData)
+
+	// 传入额外币种奖励时整体替换
+	if updateData.CurrencyRewards != nil {
+		tx.Where("task_id = ?", task.ID).Delete(&models.TaskCurrencyReward{})
+		for _, reward := range updateData.CurrencyRewards {
+			tx.Create(&models.TaskCurrencyReward{TaskID: task.ID, Currency: reward.Currency, Amount: reward.Amount})
+		}
+	}
+	tx.Commit()
+
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
 // Delete 删除任务
 func (tc *TaskController) Delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := database.DB.Delete(&models.Task{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.Task{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
@@ -87,41 +100,44 @@ func (tc *TaskController) Delete(c *gin.Context) {
 func (tc *TaskController) UserTaskList(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 
-	// 获取所有激活的任务
+	// 获取所有激活的任务: 全站任务和所在家庭的任务
 	var tasks []models.Task
-	database.DB.Where("is_active = ?", true).Order("sort").Find(&tasks)
+	database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		Preload("CurrencyRewards").Where("is_active = ?", true).Order("sort").Find(&tasks)
 
-	// 获取用户今日已完成的任务
+	// 获取用户今日已完成的任务, 已驳回的记录不计
 	today := time.Now().Format("2006-01-02")
-	var completedTaskIDs []uint
-	database.DB.Model(&models.UserTask{}).
-		Where("user_id = ? AND DATE(completed_at) = ?", userID, today).
-		Pluck("task_id", &completedTaskIDs)
+	var completedTasks []models.UserTask
+	database.Tenant(c).Select("task_id", "status").
+		Where("user_id = ? AND DATE(completed_at) = ? AND status <> ?", userID, today, services.TaskRejected).
+		Find(&completedTasks)
 
 	// 构建返回结构
 	type TaskWithStatus struct {
 		models.Task
 		Completed bool `json:"completed"`
+		Pending   bool `json:"pending"` // 已提交, 等待监护人审核
 	}
 
 	var result []TaskWithStatus
 	for _, task := range tasks {
-		completed := false
-		for _, id := range completedTaskIDs {
-			if task.ID == id {
-				completed = true
+		completed, pending := false, false
+		for _, ut := range completedTasks {
+			if task.ID == ut.TaskID {
+				completed, pending = true, ut.Status == services.TaskPending
 				break
 			}
 		}
 		// 一次性任务检查是否已完成过
 		if task.Type == "once" && !completed {
-			var count int64
-			database.DB.Model(&models.UserTask{}).
-				Where("user_id = ? AND task_id = ?", userID, task.ID).
-				Count(&count)
-			completed = count > 0
+			var userTask models.UserTask
+			if database.Tenant(c).Select("status").
+				Where("user_id = ? AND task_id = ? AND status <> ?", userID, task.ID, services.TaskRejected).
+				First(&userTask).Error == nil {
+				completed, pending = true, userTask.Status == services.TaskPending
+			}
 		}
-		result = append(result, TaskWithStatus{Task: task, Completed: completed})
+		result = append(result, TaskWithStatus{Task: task, Completed: completed, Pending: pending})
 	}
 
 	utils.Success(c, result)
@@ -134,7 +150,8 @@ func (tc *TaskController) CompleteTask(c *gin.Context) {
 
 	// 获取任务信息
 	var task models.Task
-	if err := database.DB.First(&task, taskID).Error; err != nil {
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		Preload("CurrencyRewards").First(&task, taskID).Error; err != nil {
 		utils.Fail(c, "任务不存在")
 		return
 	}
@@ -144,19 +161,20 @@ func (tc *TaskController) CompleteTask(c *gin.Context) {
 		return
 	}
 
-	// 检查任务是否已完成
+	// 检查任务是否已完成, 被驳回的记录可重新提交
 	today := time.Now().Format("2006-01-02")
 	var count int64
 
 	if task.Type == "daily" {
 		// 每日任务：检查今天是否已完成
-		database.DB.Model(&models.UserTask{}).
-			Where("user_id = ? AND task_id = ? AND DATE(completed_at) = ?", userID, taskID, today).
+		database.Tenant(c).Model(&models.UserTask{}).
+			Where("user_id = ? AND task_id = ? AND DATE(completed_at) = ? AND status <> ?",
+				userID, taskID, today, services.TaskRejected).
 			Count(&count)
 	} else {
 		// 一次性任务：检查是否已完成过
-		database.DB.Model(&models.UserTask{}).
-			Where("user_id = ? AND task_id = ?", userID, taskID).
+		database.Tenant(c).Model(&models.UserTask{}).
+			Where("user_id = ? AND task_id = ? AND status <> ?", userID, taskID, services.TaskRejected).
 			Count(&count)
 	}
 
@@ -166,89 +184,41 @@ func (tc *TaskController) CompleteTask(c *gin.Context) {
 	}
 
 	// 开始事务
-	tx := database.DB.Begin()
+	tx := database.Tenant(c).Begin()
 
-	// 记录完成
-	userTask := models.UserTask{
-		UserID:      userID,
-		TaskID:      uint(taskID),
-		CompletedAt: time.Now(),
-	}
-	if err := tx.Create(&userTask).Error; err != nil {
+	// 记录完成并发放奖励
+	payout, err := services.CompleteTask(tx, userID, &task)
+	if err != nil {
 		tx.Rollback()
+		if errors.Is(err, services.ErrHouseholdSelfTask) {
+			utils.Fail(c, err.Error())
+			return
+		}
 		utils.Fail(c, "完成任务失败")
 		return
 	}
 
-	// 更新用户金币和经验
-	var user models.SysUser
-	tx.First(&user, userID)
-
-	newGold := user.Gold + task.GoldReward
-	newExp := user.Exp + task.ExpReward
-	newLevel := calculateLevel(newExp)
-
-	tx.Model(&user).Updates(map[string]interface{}{
-		"gold":  newGold,
-		"exp":   newExp,
-		"level": newLevel,
-	})
-
-	// 记录金币流水
-	if task.GoldReward > 0 {
-		goldLog := models.UserLog{
-			UserID:      userID,
-			Type:        "gold_in",
-			Amount:      task.GoldReward,
-			Balance:     newGold,
-			Description: "完成任务: " + task.Title,
-			RefType:     "task",
-			RefID:       uint(taskID),
-		}
-		tx.Create(&goldLog)
-	}
+	tx.Commit()
 
-	// 记录经验流水
-	if task.ExpReward > 0 {
-		expLog := models.UserLog{
-			UserID:      userID,
-			Type:        "exp_in",
-			Amount:      task.ExpReward,
-			Balance:     newExp,
-			Description: "完成任务: " + task.Title,
-			RefType:     "task",
-			RefID:       uint(taskID),
-		}
-		tx.Create(&expLog)
+	if payout.Pending {
+		utils.SuccessWithMessage(c, "已提交, 等待监护人审核", gin.H{"pending": true})
+		return
 	}
 
-	tx.Commit()
-
 	// 返回奖励信息
 	utils.Success(c, gin.H{
-		"goldReward": task.GoldReward,
-		"expReward":  task.ExpReward,
-		"newGold":    newGold,
-		"newExp":     newExp,
-		"newLevel":   newLevel,
-		"levelUp":    newLevel > user.Level,
+		"goldReward":      payout.GoldReward,
+		"expReward":       payout.ExpReward,
+		"currencyRewards": payout.CurrencyRewards,
+		"goldOverflow":    payout.GoldOverflow,
+		"expOverflow":     payout.ExpOverflow,
+		"converted":       payout.Converted,
+		"newGold":         payout.NewGold,
+		"newExp":          payout.Exp.Exp,
+		"newLevel":        payout.Exp.Level,
+		"levelUp":         payout.Exp.Level > payout.Exp.PrevLevel,
+		"seasonPoints":    payout.SeasonPoints,
+		"guildWeek":       payout.GuildWeek,
+		"bossDamage":      payout.BossDamage,
 	})
 }
-
-// calculateLevel 根据经验计算等级
-func calculateLevel(exp int) int {
-	// 等级公式: 每升一级需要的经验 = 等级 * 100
-	// Level 1: 0-99, Level 2: 100-299, Level 3: 300-599, ...
-	level := 1
-	requiredExp := 0
-	for {
-		requiredExp += level * 100
-		if exp < requiredExp {
-			return level
-		}
-		level++
-		if level > 100 { // 最高100级
-			return 100
-		}
-	}
-}
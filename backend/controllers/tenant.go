@@ -0,0 +1,158 @@
+// Package controllers 空间控制器
+package controllers
+
+import (
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/models"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TenantController 空间控制器 (平台管理员)
+type TenantController struct{}
+
+// CreateTenantRequest 创建空间请求, 同时创建该空间的管理员账号
+type CreateTenantRequest struct {
+	Code          string `json:"code" binding:"required,max=50"`
+	Name          string `json:"name" binding:"required,max=100"`
+	AdminUsername string `json:"adminUsername" binding:"required,min=3,max=50"`
+	AdminPassword string `json:"adminPassword" binding:"required,min=6"`
+	AdminNickname string `json:"adminNickname"`
+}
+
+// UpdateTenantRequest 修改空间请求
+type UpdateTenantRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	IsActive bool   `json:"isActive"`
+}
+
+// List 空间列表
+func (tc *TenantController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	name := c.Query("name")
+
+	var tenants []models.Tenant
+	var total int64
+
+	query := database.DB.Model(&models.Tenant{})
+	if name != "" {
+		query = query.Where("name LIKE ? OR code LIKE ?", "%"+name+"%", "%"+name+"%")
+	}
+
+	query.Count(&total)
+	query.Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&tenants)
+
+	// 各空间用户数
+	type TenantWithCount struct {
+		models.Tenant
+		UserCount int64 `json:"userCount"`
+	}
+	result := make([]TenantWithCount, 0, len(tenants))
+	for _, tenant := range tenants {
+		item := TenantWithCount{Tenant: tenant}
+		database.DB.Model(&models.SysUser{}).Where("tenant_id = ?", tenant.ID).Count(&item.UserCount)
+		result = append(result, item)
+	}
+
+	utils.PageSuccess(c, result, total, page, pageSize)
+}
+
+// Create 创建空间及其管理员
+func (tc *TenantController) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.Tenant{}).Unscoped().Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "空间标识已存在")
+		return
+	}
+	database.DB.Model(&models.SysUser{}).Where("username = ?", req.AdminUsername).Count(&count)
+	if count > 0 {
+		utils.Fail(c, "用户名已存在")
+		return
+	}
+
+	var adminRole models.SysRole
+	if err := database.DB.Where(&models.SysRole{Key: "admin"}).First(&adminRole).Error; err != nil {
+		utils.Fail(c, "管理员角色不存在")
+		return
+	}
+
+	tenant := models.Tenant{Code: req.Code, Name: req.Name, IsActive: true}
+	tx := database.DB.Begin()
+	if err := tx.Create(&tenant).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.AdminPassword), bcrypt.DefaultCost)
+	admin := models.SysUser{
+		Username: req.AdminUsername,
+		Password: string(hashedPassword),
+		Nickname: req.AdminNickname,
+		RoleID:   adminRole.ID,
+		TenantID: tenant.ID,
+		Level:    1,
+		Status:   1,
+	}
+	if err := tx.Create(&admin).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "创建管理员失败")
+		return
+	}
+
+	// 默认主题配置
+	if err := tx.Create(&models.ThemeConfig{
+		TenantID:       tenant.ID,
+		PrimaryColor:   "#1989fa",
+		SecondaryColor: "#ff976a",
+		GoldColor:      "#ffd700",
+		ExpColor:       "#07c160",
+	}).Error; err != nil {
+		tx.Rollback()
+		utils.Fail(c, "创建失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "创建成功", gin.H{"tenant": tenant, "admin": admin})
+}
+
+// Update 修改空间名称或启用状态, 停用后该空间用户无法登录
+func (tc *TenantController) Update(c *gin.Context) {
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, c.Param("id")).Error; err != nil {
+		utils.Fail(c, "空间不存在")
+		return
+	}
+
+	var req UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if tenant.ID == database.DefaultTenantID && !req.IsActive {
+		utils.Fail(c, "不能停用默认空间")
+		return
+	}
+
+	if err := database.DB.Model(&tenant).Updates(map[string]interface{}{
+		"name":      req.Name,
+		"is_active": req.IsActive,
+	}).Error; err != nil {
+		utils.Fail(c, "更新失败")
+		return
+	}
+	utils.SuccessWithMessage(c, "更新成功", tenant)
+}
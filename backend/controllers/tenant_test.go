@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenantCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		existing    int64 // 已存在的同标识空间或同名用户数
+		hasRole     bool
+		wantCode    int
+		wantMessage string
+		wantInserts []string
+	}{
+		{"创建空间和管理员", 0, true, 0, "创建成功", []string{"tenant", "sys_user", "theme_config"}},
+		{"空间标识已存在", 1, true, -1, "空间标识已存在", nil},
+		{"管理员角色不存在", 0, false, -1, "管理员角色不存在", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executed := openFakeDB(t, func(query string, args []driver.NamedValue) (*fakeResult, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT count(*)"):
+					return &fakeResult{columns: []string{"count(*)"}, rows: [][]driver.Value{{tt.existing}}}, nil
+				case isSelect(query, "sys_role") && tt.hasRole:
+					return &fakeResult{
+						columns: []string{"id", "name", "key"},
+						rows:    [][]driver.Value{{int64(1), "超级管理员", "admin"}},
+					}, nil
+				}
+				return nil, nil
+			})
+
+			body := `{"code":"family","name":"家庭空间","adminUsername":"owner","adminPassword":"123456"}`
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/tenants", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			(&TenantController{}).Create(c)
+
+			var resp utils.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("响应解析失败: %v", err)
+			}
+			if resp.Code != tt.wantCode || resp.Message != tt.wantMessage {
+				t.Fatalf("响应 = (%d, %s), want (%d, %s)", resp.Code, resp.Message, tt.wantCode, tt.wantMessage)
+			}
+
+			var inserts []string
+			for _, query := range *executed {
+				if strings.HasPrefix(query, "INSERT INTO `") {
+					inserts = append(inserts, strings.SplitN(query, "`", 3)[1])
+				}
+			}
+			if strings.Join(inserts, ",") != strings.Join(tt.wantInserts, ",") {
+				t.Errorf("写入的表 = %v, want %v", inserts, tt.wantInserts)
+			}
+		})
+	}
+}
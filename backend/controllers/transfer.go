@@ -0,0 +1,181 @@
+// Package controllers 转账赠礼控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TransferController 转账赠礼控制器
+type TransferController struct{}
+
+// TransferRequest 转账请求
+type TransferRequest struct {
+	ToUsername string `json:"toUsername" binding:"required"`
+	Amount     int    `json:"amount" binding:"required,gt=0"`
+	Remark     string `json:"remark" binding:"max=255"`
+}
+
+// GiftRequest 赠礼请求
+type GiftRequest struct {
+	ToUsername string `json:"toUsername" binding:"required"`
+	Remark     string `json:"remark" binding:"max=255"`
+}
+
+// List 转账记录 (管理端)
+func (tc *TransferController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	userID := c.Query("userId")
+	transferType := c.Query("type")
+
+	var transfers []models.Transfer
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Transfer{})
+	if userID != "" {
+		query = query.Where("from_user_id = ? OR to_user_id = ?", userID, userID)
+	}
+	if transferType != "" {
+		query = query.Where("type = ?", transferType)
+	}
+
+	query.Count(&total)
+	query.Preload("FromUser").Preload("ToUser").Preload("Reward", services.Unscoped).
+		Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&transfers)
+
+	utils.PageSuccess(c, transfers, total, page, pageSize)
+}
+
+// ===== 用户端接口 =====
+
+// UserTransferList 我的转账赠礼记录 (H5端)
+func (tc *TransferController) UserTransferList(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	direction := c.Query("direction") // in收到 out转出
+
+	var transfers []models.Transfer
+	var total int64
+
+	query := database.Tenant(c).Model(&models.Transfer{})
+	switch direction {
+	case "in":
+		query = query.Where("to_user_id = ?", userID)
+	case "out":
+		query = query.Where("from_user_id = ?", userID)
+	default:
+		query = query.Where("from_user_id = ? OR to_user_id = ?", userID, userID)
+	}
+
+	query.Count(&total)
+	query.Preload("FromUser", userBriefPreload).Preload("ToUser", userBriefPreload).Preload("Reward", services.Unscoped).
+		Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&transfers)
+
+	utils.PageSuccess(c, transfers, total, page, pageSize)
+}
+
+// userBriefPreload 预加载用户时只取公开字段
+func userBriefPreload(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Select("id", "username", "nickname", "avatar", "level")
+}
+
+// Usage 今日转账额度
+func (tc *TransferController) Usage(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	utils.Success(c, services.GetTransferUsage(database.Tenant(c), userID))
+}
+
+// Send 金币转账
+func (tc *TransferController) Send(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var receiver models.SysUser
+	if err := database.Tenant(c).Where("username = ?", req.ToUsername).First(&receiver).Error; err != nil {
+		utils.Fail(c, services.ErrTransferReceiver.Error())
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	transfer, err := services.SendGold(tx, userID, receiver.ID, req.Amount, req.Remark)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, transferError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "转账成功", transfer)
+}
+
+// Gift 赠送奖励
+func (tc *TransferController) Gift(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	rewardID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req GiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	var reward models.Reward
+	if err := database.Tenant(c).Scopes(services.HouseholdScope(database.Tenant(c), userID)).
+		First(&reward, rewardID).Error; err != nil || !reward.IsActive {
+		utils.Fail(c, "奖励不存在或已下架")
+		return
+	}
+	if reward.Type == services.RewardTypeChest {
+		utils.Fail(c, "宝箱不支持赠送")
+		return
+	}
+
+	var receiver models.SysUser
+	if err := database.Tenant(c).Where("username = ?", req.ToUsername).First(&receiver).Error; err != nil {
+		utils.Fail(c, services.ErrTransferReceiver.Error())
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	transfer, err := services.GiftReward(tx, userID, receiver.ID, &reward, req.Remark)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, transferError(err))
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "赠送成功", transfer)
+}
+
+// transferError 转换转账失败原因
+func transferError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrTransferSelf), errors.Is(err, services.ErrTransferReceiver),
+		errors.Is(err, services.ErrTransferSenderLocked), errors.Is(err, services.ErrTransferLevel),
+		errors.Is(err, services.ErrTransferAccountAge), errors.Is(err, services.ErrTransferAmount),
+		errors.Is(err, services.ErrTransferDailyLimit), errors.Is(err, services.ErrTransferDailyCount),
+		errors.Is(err, services.ErrTransferReceiveLimit), errors.Is(err, services.ErrInsufficientGold),
+		errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrOutOfStock),
+		errors.Is(err, services.ErrGiftRewardHidden):
+		return err.Error()
+	default:
+		return "操作失败"
+	}
+}
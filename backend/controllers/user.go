@@ -2,14 +2,18 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"life-rpg/database"
+	"life-rpg/middleware"
 	"life-rpg/models"
+	"life-rpg/services"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // UserController 用户控制器
@@ -33,7 +37,7 @@ func (uc *UserController) List(c *gin.Context) {
 	var users []models.SysUser
 	var total int64
 
-	query := database.DB.Model(&models.SysUser{}).Preload("Role")
+	query := database.Tenant(c).Model(&models.SysUser{}).Preload("Role")
 	if username != "" {
 		query = query.Where("username LIKE ?", "%"+username+"%")
 	}
@@ -67,6 +71,15 @@ func (uc *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	if user.Email != "" {
+		email, err := services.NormalizeEmail(user.Email)
+		if err != nil {
+			utils.Fail(c, err.Error())
+			return
+		}
+		user.Email = email
+	}
+
 	// 加密密码
 	if user.Password != "" {
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -77,7 +90,10 @@ func (uc *UserController) Create(c *gin.Context) {
 		user.Password = string(hashedPassword)
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
+	// 初始余额需通过调整接口发放
+	user.Gold, user.Exp, user.Level = 0, 0, 1
+
+	if err := database.Tenant(c).Create(&user).Error; err != nil {
 		utils.Fail(c, "创建失败")
 		return
 	}
@@ -97,7 +113,7 @@ func (uc *UserController) Create(c *gin.Context) {
 func (uc *UserController) Update(c *gin.Context) {
 	id := c.Param("id")
 	var user models.SysUser
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := database.Tenant(c).First(&user, id).Error; err != nil {
 		utils.Fail(c, "用户不存在")
 		return
 	}
@@ -108,6 +124,15 @@ func (uc *UserController) Update(c *gin.Context) {
 		return
 	}
 
+	if updateData.Email != "" {
+		email, err := services.NormalizeEmail(updateData.Email)
+		if err != nil {
+			utils.Fail(c, err.Error())
+			return
+		}
+		updateData.Email = email
+	}
+
 	// 如果传了新密码则加密
 	if updateData.Password != "" {
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(updateData.Password), bcrypt.DefaultCost)
@@ -116,7 +141,8 @@ func (uc *UserController) Update(c *gin.Context) {
 		updateData.Password = user.Password
 	}
 
-	database.DB.Model(&user).Updates(updateData)
+	// 金币、经验、等级只能通过调整接口变动，保证每次变动都有流水
+	database.Tenant(c).Model(&user).Omit("gold", "exp", "level").Updates(updateData)
 	utils.SuccessWithMessage(c, "更新成功", nil)
 }
 
@@ -137,7 +163,7 @@ func (uc *UserController) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&models.SysUser{}, id).Error; err != nil {
+	if err := database.Tenant(c).Delete(&models.SysUser{}, id).Error; err != nil {
 		utils.Fail(c, "删除失败")
 		return
 	}
@@ -157,7 +183,127 @@ func (uc *UserController) ResetPassword(c *gin.Context) {
 	id := c.Param("id")
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
-	database.DB.Model(&models.SysUser{}).Where("id = ?", id).Update("password", string(hashedPassword))
+	database.Tenant(c).Model(&models.SysUser{}).Where("id = ?", id).Update("password", string(hashedPassword))
 
 	utils.SuccessWithMessage(c, "密码已重置为123456", nil)
 }
+
+// AdjustRequest 余额调整请求
+type AdjustRequest struct {
+	Gold       int            `json:"gold"`
+	Exp        int            `json:"exp"`
+	Currencies map[string]int `json:"currencies"` // 其他币种调整, 如 {"gem": 10}
+	Reason     string         `json:"reason" binding:"required"`
+}
+
+// Adjust 调整用户金币/经验
+// @Summary 管理员发放或扣除用户金币、经验
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param data body AdjustRequest true "调整信息, 正数发放负数扣除"
+// @Success 200 {object} utils.Response
+// @Router /api/users/{id}/adjust [post]
+func (uc *UserController) Adjust(c *gin.Context) {
+	operatorID := middleware.GetCurrentUserID(c)
+	userID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req AdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "请填写调整原因")
+		return
+	}
+	if req.Gold == 0 && req.Exp == 0 && len(req.Currencies) == 0 {
+		utils.Fail(c, "调整数量不能为0")
+		return
+	}
+
+	meta := services.LogMeta{
+		Description: "管理员调整: " + req.Reason,
+		RefType:     "admin",
+		RefID:       operatorID,
+		OperatorID:  operatorID,
+	}
+
+	tx := database.Tenant(c).Begin()
+
+	newGold, err := services.ChangeGold(tx, uint(userID), req.Gold, meta)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, adjustError(err))
+		return
+	}
+	expResult, err := services.ChangeExp(tx, uint(userID), req.Exp, meta)
+	if err != nil {
+		tx.Rollback()
+		utils.Fail(c, adjustError(err))
+		return
+	}
+	for currency, amount := range req.Currencies {
+		if _, err := services.ChangeCurrency(tx, uint(userID), currency, amount, meta); err != nil {
+			tx.Rollback()
+			utils.Fail(c, adjustError(err))
+			return
+		}
+	}
+
+	tx.Commit()
+
+	balances, _ := services.Balances(database.Tenant(c), uint(userID))
+	utils.SuccessWithMessage(c, "调整成功", gin.H{
+		"newGold":  newGold,
+		"newExp":   expResult.Exp,
+		"newLevel": expResult.Level,
+		"wallet":   balances,
+	})
+}
+
+// adjustError 转换调整失败原因
+func adjustError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "用户不存在"
+	case errors.Is(err, services.ErrInsufficientGold), errors.Is(err, services.ErrInsufficientBalance),
+		errors.Is(err, services.ErrInsufficientExp), errors.Is(err, services.ErrUnknownCurrency):
+		return err.Error()
+	default:
+		return "调整失败"
+	}
+}
+
+// Logs 用户流水记录
+// @Summary 获取指定用户的流水记录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param type query string false "流水类型"
+// @Param currency query string false "币种"
+// @Success 200 {object} utils.Response
+// @Router /api/users/{id}/logs [get]
+func (uc *UserController) Logs(c *gin.Context) {
+	id := c.Param("id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	logType := c.Query("type")
+	currency := c.Query("currency")
+
+	var logs []models.UserLog
+	var total int64
+
+	query := database.Tenant(c).Model(&models.UserLog{}).Where("user_id = ?", id)
+	if logType != "" {
+		query = query.Where("type = ?", logType)
+	}
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs)
+
+	utils.PageSuccess(c, logs, total, page, pageSize)
+}
@@ -0,0 +1,204 @@
+// Package controllers Webhook控制器
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"life-rpg/database"
+	"life-rpg/middleware"
+	"life-rpg/models"
+	"life-rpg/services"
+	"life-rpg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookController Webhook控制器, Admin 为true时管理空间级订阅, 否则管理当前用户的订阅
+type WebhookController struct {
+	Admin bool
+}
+
+// WebhookRequest 创建/更新订阅请求, events 为逗号分隔的事件
+type WebhookRequest struct {
+	Name         string `json:"name"`
+	URL          string `json:"url" binding:"required"`
+	Events       string `json:"events" binding:"required"`
+	IsActive     *bool  `json:"isActive"`
+	RotateSecret bool   `json:"rotateSecret"` // 更新时重新生成签名密钥
+}
+
+// owner 订阅所属用户, 空间级订阅为0
+func (wc *WebhookController) owner(c *gin.Context) uint {
+	if wc.Admin {
+		return 0
+	}
+	return middleware.GetCurrentUserID(c)
+}
+
+// find 查找当前身份可管理的订阅
+func (wc *WebhookController) find(c *gin.Context) (*models.Webhook, bool) {
+	var hook models.Webhook
+	if err := database.Tenant(c).Where("id = ? AND user_id = ?", c.Param("id"), wc.owner(c)).
+		First(&hook).Error; err != nil {
+		utils.Fail(c, services.ErrWebhookNotFound.Error())
+		return nil, false
+	}
+	return &hook, true
+}
+
+// Events 可订阅的事件
+func (wc *WebhookController) Events(c *gin.Context) {
+	utils.Success(c, services.WebhookEvents)
+}
+
+// List 订阅列表
+func (wc *WebhookController) List(c *gin.Context) {
+	var hooks []models.Webhook
+	database.Tenant(c).Where("user_id = ?", wc.owner(c)).Order("id desc").Find(&hooks)
+	utils.Success(c, hooks)
+}
+
+// Create 创建订阅
+func (wc *WebhookController) Create(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+	if err := services.CheckWebhookLimit(database.Tenant(c), wc.owner(c)); err != nil {
+		utils.Fail(c, err.Error())
+		return
+	}
+
+	hook := models.Webhook{
+		UserID:   wc.owner(c),
+		Name:     req.Name,
+		URL:      req.URL,
+		Events:   req.Events,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		hook.IsActive = *req.IsActive
+	}
+	if err := services.NormalizeWebhook(&hook); err != nil {
+		utils.Fail(c, webhookError(err))
+		return
+	}
+	if err := database.Tenant(c).Create(&hook).Error; err != nil {
+		utils.Fail(c, "创建失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "创建成功", hook)
+}
+
+// Update 更新订阅
+func (wc *WebhookController) Update(c *gin.Context) {
+	hook, ok := wc.find(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "参数错误")
+		return
+	}
+
+	hook.Name, hook.URL, hook.Events = req.Name, req.URL, req.Events
+	if req.IsActive != nil {
+		hook.IsActive = *req.IsActive
+	}
+	if req.RotateSecret {
+		hook.Secret = ""
+	}
+	if err := services.NormalizeWebhook(hook); err != nil {
+		utils.Fail(c, webhookError(err))
+		return
+	}
+
+	database.Tenant(c).Model(hook).Select("name", "url", "events", "is_active", "secret").Updates(hook)
+	utils.SuccessWithMessage(c, "更新成功", hook)
+}
+
+// Delete 删除订阅及投递记录
+func (wc *WebhookController) Delete(c *gin.Context) {
+	hook, ok := wc.find(c)
+	if !ok {
+		return
+	}
+
+	tx := database.Tenant(c).Begin()
+	if err := services.DeleteWebhook(tx, hook); err != nil {
+		tx.Rollback()
+		utils.Fail(c, "删除失败")
+		return
+	}
+	tx.Commit()
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// Deliveries 订阅的投递记录
+func (wc *WebhookController) Deliveries(c *gin.Context) {
+	hook, ok := wc.find(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	query := database.Tenant(c).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if event := c.Query("event"); event != "" {
+		query = query.Where("event = ?", event)
+	}
+
+	var total int64
+	var deliveries []models.WebhookDelivery
+	query.Count(&total)
+	query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries)
+
+	utils.PageSuccess(c, deliveries, total, page, pageSize)
+}
+
+// Test 立即推送一次测试事件并返回投递结果
+func (wc *WebhookController) Test(c *gin.Context) {
+	hook, ok := wc.find(c)
+	if !ok {
+		return
+	}
+
+	var user models.SysUser
+	if err := database.Tenant(c).First(&user, middleware.GetCurrentUserID(c)).Error; err != nil {
+		utils.Fail(c, "用户不存在")
+		return
+	}
+
+	delivery, err := services.SendTestWebhook(database.Tenant(c), hook, &user)
+	if err != nil {
+		utils.Fail(c, "推送失败")
+		return
+	}
+	if delivery.Status != services.DeliverySuccess {
+		utils.SuccessWithMessage(c, "推送失败: "+delivery.LastError, delivery)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "推送成功", delivery)
+}
+
+// webhookError Webhook错误转为提示
+func webhookError(err error) string {
+	switch {
+	case errors.Is(err, services.ErrWebhookURL),
+		errors.Is(err, services.ErrWebhookEvents),
+		errors.Is(err, services.ErrWebhookLimit):
+		return err.Error()
+	default:
+		return "保存失败"
+	}
+}
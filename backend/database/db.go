@@ -53,6 +53,14 @@ func InitDB() {
 
 	log.Println("数据库连接成功")
 
+	// 事务提交后回调, 用于实时事件推送
+	installHookPool(DB)
+
+	// 租户隔离
+	if err := registerTenantScope(DB); err != nil {
+		log.Fatalf("注册租户隔离回调失败: %v", err)
+	}
+
 	// 自动迁移
 	autoMigrate()
 }
@@ -70,9 +78,93 @@ func autoMigrate() {
 		&models.UserLog{},
 		&models.Announcement{},
 		&models.ThemeConfig{},
+		&models.Currency{},
+		&models.UserWallet{},
+		&models.TaskCurrencyReward{},
+		&models.SavingsGoal{},
+		&models.BankProduct{},
+		&models.BankDeposit{},
+		&models.Transfer{},
+		&models.ChestDrop{},
+		&models.ChestOpen{},
+		&models.UserItem{},
+		&models.SaleEvent{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.EarningCap{},
+		&models.CheckinReward{},
+		&models.UserCheckin{},
+		&models.Season{},
+		&models.SeasonTier{},
+		&models.UserSeason{},
+		&models.SeasonClaim{},
+		&models.Friendship{},
+		&models.UserBlock{},
+		&models.UserPrivacy{},
+		&models.Guild{},
+		&models.GuildMember{},
+		&models.GuildWeek{},
+		&models.Boss{},
+		&models.BossParticipant{},
+		&models.BossDamage{},
+		&models.LeaderboardEntry{},
+		&models.Challenge{},
+		&models.ChallengeParticipant{},
+		&models.FeedEvent{},
+		&models.FeedReaction{},
+		&models.FeedComment{},
+		&models.Notification{},
+		&models.Accountability{},
+		&models.AccountabilityTask{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.Tenant{},
+		&models.TaskReminder{},
+		&models.EmailMessage{},
+		&models.PasswordReset{},
+		&models.EmailVerification{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	)
 	if err != nil {
 		log.Fatalf("数据库迁移失败: %v", err)
 	}
+
+	// 多币种上线前的经验流水默认记为金币，修正为exp
+	DB.Model(&models.UserLog{}).
+		Where("type IN ? AND currency = ?", []string{"exp_in", "exp_out"}, "gold").
+		Update("currency", "exp")
+
+	// 空间隔离覆盖到公会、动态等数据之前的记录, 按所属用户回填空间
+	for table, userColumn := range map[string]string{
+		"guild":        "leader_id",
+		"challenge":    "creator_id",
+		"feed_event":   "user_id",
+		"bank_deposit": "user_id",
+		"transfer":     "from_user_id",
+	} {
+		DB.Exec(fmt.Sprintf("UPDATE `%s` t JOIN sys_user u ON u.id = t.%s SET t.tenant_id = u.tenant_id WHERE t.tenant_id <> u.tenant_id",
+			table, userColumn))
+	}
+
+	// 家庭任务不再支持额外币种奖励, 清理此前设置的记录
+	DB.Exec("DELETE r FROM task_currency_reward r JOIN task t ON t.id = r.task_id WHERE t.household_id <> 0")
+
+	// 已发送的重置密码邮件不再保留含令牌的正文
+	DB.Exec("UPDATE email_message SET body = '' WHERE template = 'password_reset' AND status <> 'pending'")
+
+	// 公会名和优惠券码改为空间内唯一, 移除原全局唯一索引
+	dropLegacyIndex(&models.Guild{}, "idx_guild_name")
+	dropLegacyIndex(&models.Coupon{}, "idx_coupon_code")
 	log.Println("数据库迁移完成")
 }
+
+// dropLegacyIndex 删除已被替换的旧索引
+func dropLegacyIndex(model interface{}, name string) {
+	if !DB.Migrator().HasIndex(model, name) {
+		return
+	}
+	if err := DB.Migrator().DropIndex(model, name); err != nil {
+		log.Printf("删除旧索引 %s 失败: %v", name, err)
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// hookPool 包装连接池, 使开启的事务支持提交后回调
+type hookPool struct {
+	*sql.DB
+}
+
+// BeginTx 开启事务
+func (p *hookPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &hookTx{Tx: tx}, nil
+}
+
+// GetDBConn 底层连接池, 供 gorm.DB.DB() 使用
+func (p *hookPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+// hookTx 支持提交后回调的事务
+type hookTx struct {
+	*sql.Tx
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// AfterCommit 注册提交成功后执行的回调, 回滚时丢弃
+func (t *hookTx) AfterCommit(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks = append(t.hooks, fn)
+}
+
+// Commit 提交事务并执行回调
+func (t *hookTx) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	hooks := t.hooks
+	t.hooks = nil
+	t.mu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+	return nil
+}
+
+// Rollback 回滚事务并丢弃回调
+func (t *hookTx) Rollback() error {
+	t.mu.Lock()
+	t.hooks = nil
+	t.mu.Unlock()
+	return t.Tx.Rollback()
+}
+
+// installHookPool 替换连接池以支持提交后回调
+func installHookPool(db *gorm.DB) {
+	if sqlDB, ok := db.ConnPool.(*sql.DB); ok {
+		pool := &hookPool{DB: sqlDB}
+		db.ConnPool = pool
+		db.Statement.ConnPool = pool
+	}
+}
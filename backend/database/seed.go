@@ -11,6 +11,13 @@ import (
 
 // SeedData 初始化种子数据
 func SeedData() {
+	// 币种数据独立初始化, 已有数据的系统升级后同样需要
+	seedDefaultTenant()
+	seedCurrencies()
+	seedCheckinCalendar()
+	// 监护人角色在基础角色之后补充, 保证管理员角色ID为1
+	defer seedGuardianRole()
+
 	// 检查是否已有角色数据
 	var roleCount int64
 	DB.Model(&models.SysRole{}).Count(&roleCount)
@@ -115,3 +122,55 @@ func SeedData() {
 
 	log.Println("种子数据初始化完成!")
 }
+
+// seedDefaultTenant 初始化默认空间
+func seedDefaultTenant() {
+	tenant := models.Tenant{ID: DefaultTenantID, Code: "default", Name: "默认空间", IsActive: true}
+	DB.Where("id = ?", tenant.ID).FirstOrCreate(&tenant)
+}
+
+// seedGuardianRole 初始化监护人角色
+func seedGuardianRole() {
+	role := models.SysRole{Name: "监护人", Key: "guardian", Sort: 3, Remark: "管理家庭成员的任务、奖励和审核"}
+	// key 为MySQL保留字, 使用结构体条件由GORM加引号
+	if err := DB.Where(&models.SysRole{Key: role.Key}).FirstOrCreate(&role).Error; err != nil {
+		log.Printf("监护人角色初始化失败: %v", err)
+	}
+}
+
+// seedCurrencies 初始化默认币种
+func seedCurrencies() {
+	currencies := []models.Currency{
+		{Code: "gold", Name: "金币", Icon: "🪙", IsActive: true, Sort: 1},
+		{Code: "gem", Name: "宝石", Icon: "💎", IsActive: true, Sort: 2},
+		{Code: "token", Name: "活动代币", Icon: "🎟️", IsActive: true, Sort: 3},
+	}
+	for _, currency := range currencies {
+		DB.Where("code = ?", currency.Code).FirstOrCreate(&currency)
+	}
+}
+
+// seedCheckinCalendar 初始化签到日历: 每周递增, 每第7天额外奖励, 第30天发放月度徽章
+func seedCheckinCalendar() {
+	var count int64
+	DB.Model(&models.CheckinReward{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	var rewards []models.CheckinReward
+	for day := 1; day <= 31; day++ {
+		reward := models.CheckinReward{Day: day}
+		reward.Gold, reward.Exp = 5+(day-1)/7*5, 2
+		if day%7 == 0 {
+			reward.Gold += 20
+			reward.Currency, reward.Amount = "gem", 1
+		}
+		if day == 30 {
+			reward.ItemName, reward.ItemKind, reward.ItemRarity = "月度全勤徽章", "badge", "epic"
+		}
+		rewards = append(rewards, reward)
+	}
+	DB.Create(&rewards)
+	log.Println("签到日历创建完成")
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TenantKey 上下文中保存当前租户ID的键, 与JWT中间件写入 gin.Context 的键一致
+const TenantKey = "tenantId"
+
+// DefaultTenantID 默认空间, 历史数据和未指定空间的注册用户归属于此
+const DefaultTenantID uint = 1
+
+// tenantField 需要按租户隔离的模型字段
+const tenantField = "TenantID"
+
+// Tenant 绑定请求上下文的数据库会话, 上下文携带租户ID时查询、更新、删除自动限定在该租户,
+// 新建记录写入该租户ID且更新时不允许修改; 未携带租户ID (如公开接口和定时任务) 时不做限制
+func Tenant(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
+
+// WithTenant 返回携带指定租户ID的上下文
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	// 使用字符串键, 与 gin.Context.Value 按键名查找的方式一致
+	return context.WithValue(ctx, TenantKey, tenantID)
+}
+
+// TenantFrom 上下文中的租户ID, 0表示未限定
+func TenantFrom(ctx context.Context) uint {
+	if ctx == nil {
+		return 0
+	}
+	if id, ok := ctx.Value(TenantKey).(uint); ok {
+		return id
+	}
+	return 0
+}
+
+// registerTenantScope 注册租户隔离回调
+func registerTenantScope(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", tenantWhere); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:row", tenantWhere); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", tenantUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", tenantWhere); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant:create", tenantAssign)
+}
+
+// tenantSchemaField 当前语句的租户ID和模型的租户字段, 不需要隔离时返回nil
+func tenantSchemaField(db *gorm.DB) (uint, *schema.Field) {
+	tenantID := TenantFrom(db.Statement.Context)
+	if tenantID == 0 || db.Statement.Schema == nil {
+		return 0, nil
+	}
+	return tenantID, db.Statement.Schema.LookUpField(tenantField)
+}
+
+// tenantWhere 为查询、更新、删除追加租户条件
+func tenantWhere(db *gorm.DB) {
+	tenantID, field := tenantSchemaField(db)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// tenantUpdate 更新限定在当前租户, 且不允许修改记录所属租户
+func tenantUpdate(db *gorm.DB) {
+	tenantWhere(db)
+	if _, field := tenantSchemaField(db); field != nil {
+		db.Statement.Omits = append(db.Statement.Omits, field.DBName)
+	}
+}
+
+// tenantAssign 新建记录写入当前租户, 忽略请求中指定的租户
+func tenantAssign(db *gorm.DB) {
+	tenantID, field := tenantSchemaField(db)
+	if field == nil {
+		return
+	}
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	assign := func(v reflect.Value) {
+		db.AddError(field.Set(ctx, v, tenantID))
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
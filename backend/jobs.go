@@ -0,0 +1,90 @@
+package main
+
+import (
+	"time"
+
+	"life-rpg/database"
+	"life-rpg/scheduler"
+	"life-rpg/services"
+)
+
+// registerJobs 注册后台定时任务
+func registerJobs() {
+	scheduler.Register(scheduler.Job{
+		Name:     "bank-settle",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.SettleMaturedDeposits(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "season-archive",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.ArchiveEndedSeasons(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "boss-counter",
+		Interval: time.Hour,
+		Run: func() error {
+			return services.BossCounterAttack(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "leaderboard-refresh",
+		Interval: 5 * time.Minute,
+		Run: func() error {
+			return services.RefreshLeaderboards(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "guild-goal-settle",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.SettleGuildWeeks(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "challenge-settle",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.SettleDueChallenges(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "accountability-rollover",
+		Interval: time.Hour,
+		Run: func() error {
+			return services.EvaluateRollover(database.DB)
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "task-reminders",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.DispatchReminders(database.DB, time.Now())
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "mail-queue",
+		Interval: time.Minute,
+		Run: func() error {
+			return services.ProcessMailQueue(database.DB, time.Now())
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "weekly-summary",
+		Interval: time.Hour,
+		Run: func() error {
+			return services.SendWeeklySummaries(database.DB, time.Now())
+		},
+	})
+	scheduler.Register(scheduler.Job{
+		Name:     "webhook-delivery",
+		Interval: 10 * time.Second,
+		Run: func() error {
+			return services.ProcessWebhookDeliveries(database.DB, time.Now())
+		},
+	})
+}
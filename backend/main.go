@@ -5,11 +5,14 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"life-rpg/config"
 	"life-rpg/database"
 	"life-rpg/middleware"
 	"life-rpg/routes"
+	"life-rpg/scheduler"
+	"life-rpg/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,12 +22,24 @@ func main() {
 	config.InitConfig()
 	log.Println("配置加载完成")
 
+	// 命令行子命令 (如对账), 执行完毕后退出
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// 初始化数据库
 	database.InitDB()
 
 	// 初始化种子数据
 	database.SeedData()
 
+	// 启用邮件 (配置了 MAIL_HOST 时)
+	services.InitMail()
+
+	// 启动后台定时任务
+	registerJobs()
+	scheduler.Start()
+
 	// 创建 Gin 引擎
 	r := gin.Default()
 
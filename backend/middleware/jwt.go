@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"life-rpg/config"
+	"life-rpg/database"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -18,16 +19,18 @@ type Claims struct {
 	Username string `json:"username"`
 	RoleID   uint   `json:"roleId"`
 	RoleKey  string `json:"roleKey"`
+	TenantID uint   `json:"tenantId"` // 所属空间, 请求内的数据库查询按此隔离
 	jwt.RegisteredClaims
 }
 
 // GenerateToken 生成JWT Token
-func GenerateToken(userID uint, username string, roleID uint, roleKey string) (string, error) {
+func GenerateToken(userID uint, username string, roleID uint, roleKey string, tenantID uint) (string, error) {
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
 		RoleID:   roleID,
 		RoleKey:  roleKey,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.AppConfig.JWT.ExpireTime) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -88,6 +91,25 @@ func JWTAuth() gin.HandlerFunc {
 		c.Set("roleId", claims.RoleID)
 		c.Set("roleKey", claims.RoleKey)
 
+		// 旧版Token不含空间信息, 归入默认空间
+		tenantID := claims.TenantID
+		if tenantID == 0 {
+			tenantID = database.DefaultTenantID
+		}
+		c.Set(database.TenantKey, tenantID)
+
+		c.Next()
+	}
+}
+
+// QueryToken 将 token 参数作为认证头, 仅用于 EventSource 这类无法设置请求头的连接, 需放在 JWTAuth 之前
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
 		c.Next()
 	}
 }
@@ -107,3 +129,8 @@ func GetCurrentRoleKey(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetCurrentTenantID 从上下文获取当前空间ID
+func GetCurrentTenantID(c *gin.Context) uint {
+	return database.TenantFrom(c)
+}
@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"life-rpg/database"
 	"life-rpg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -50,5 +51,22 @@ func AdminRequired() gin.HandlerFunc {
 
 // UserRequired 用户及以上角色可访问
 func UserRequired() gin.HandlerFunc {
-	return RoleRequired("admin", "user")
+	return RoleRequired("admin", "user", "guardian")
+}
+
+// GuardianRequired 监护人及管理员可访问
+func GuardianRequired() gin.HandlerFunc {
+	return RoleRequired("guardian")
+}
+
+// PlatformAdminRequired 平台管理员可访问: 默认空间的管理员, 负责管理各空间和全局配置
+func PlatformAdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetCurrentRoleKey(c) != "admin" || GetCurrentTenantID(c) != database.DefaultTenantID {
+			utils.Forbidden(c, "权限不足")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 }
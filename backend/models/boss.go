@@ -0,0 +1,73 @@
+// Package models 首领讨伐模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Boss 限时首领活动
+type Boss struct {
+	ID              uint              `gorm:"primaryKey" json:"id"`
+	TenantID        uint              `gorm:"default:1;index" json:"tenantId"` // 所属空间
+	Name            string            `gorm:"size:50;not null" json:"name"`
+	Description     string            `gorm:"size:500" json:"description"`
+	Image           string            `gorm:"size:255" json:"image"`
+	MaxHP           int               `gorm:"not null" json:"maxHp"`
+	HP              int               `gorm:"not null" json:"hp"`
+	StartAt         time.Time         `gorm:"not null" json:"startAt"`
+	EndAt           time.Time         `gorm:"not null" json:"endAt"`
+	DamagePercent   int               `gorm:"default:100" json:"damagePercent"` // 完成任务造成伤害 = 任务经验 * 百分比
+	CounterHeal     int               `gorm:"default:0" json:"counterHeal"`     // 参与者每漏做一个每日任务, 首领回复的生命
+	CounterGold     int               `gorm:"default:0" json:"counterGold"`     // 参与者每漏做一个每日任务被扣除的金币
+	LootChestID     uint              `gorm:"default:0" json:"lootChestId"`     // 击败后为每位参与者开启的宝箱, 0不开启
+	RewardBundle    `gorm:"embedded"` // 击败后每位参与者获得的奖励
+	Status          string            `gorm:"size:20;default:active;index" json:"status"` // active进行中 defeated已击败 expired已逃走
+	DefeatedAt      *time.Time        `json:"defeatedAt"`
+	LastCounterDate string            `gorm:"size:10" json:"lastCounterDate"` // 最近一次结算反击的日期
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Boss) TableName() string {
+	return "boss"
+}
+
+// BossParticipant 首领活动参与者
+type BossParticipant struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	BossID   uint      `gorm:"uniqueIndex:idx_boss_user;not null" json:"bossId"`
+	UserID   uint      `gorm:"uniqueIndex:idx_boss_user;not null" json:"userId"`
+	User     *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Damage   int       `gorm:"default:0" json:"damage"` // 累计造成伤害
+	Missed   int       `gorm:"default:0" json:"missed"` // 累计漏做每日任务数
+	Rewarded bool      `gorm:"default:false" json:"rewarded"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// TableName 表名
+func (BossParticipant) TableName() string {
+	return "boss_participant"
+}
+
+// BossDamage 首领战斗记录
+type BossDamage struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	BossID      uint      `gorm:"index;not null" json:"bossId"`
+	UserID      uint      `gorm:"index;not null" json:"userId"`
+	User        *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Kind        string    `gorm:"size:20;not null" json:"kind"` // attack攻击 counter反击
+	Damage      int       `json:"damage"`                       // attack为对首领造成的伤害, counter为首领回复的生命
+	GoldLost    int       `gorm:"default:0" json:"goldLost"`    // 反击扣除的金币
+	TaskID      uint      `gorm:"default:0" json:"taskId"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (BossDamage) TableName() string {
+	return "boss_damage"
+}
@@ -0,0 +1,49 @@
+// Package models 挑战模型
+package models
+
+import "time"
+
+// Challenge 好友挑战: 在期限内完成指定任务 Target 次, 可设置金币赌注
+type Challenge struct {
+	ID           uint                   `gorm:"primaryKey" json:"id"`
+	TenantID     uint                   `gorm:"default:1;index" json:"tenantId"` // 所属空间
+	CreatorID    uint                   `gorm:"index;not null" json:"creatorId"`
+	Creator      *SysUser               `gorm:"foreignKey:CreatorID" json:"creator,omitempty"`
+	TaskID       uint                   `gorm:"index;not null" json:"taskId"`
+	Task         *Task                  `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	Target       int                    `gorm:"not null" json:"target"`                     // 需完成次数
+	Days         int                    `gorm:"default:7" json:"days"`                      // 挑战天数
+	Stake        int                    `gorm:"default:0" json:"stake"`                     // 每人押注金币, 0为无赌注
+	Pot          int                    `gorm:"default:0" json:"pot"`                       // 奖池: 已托管的押注总额
+	Status       string                 `gorm:"size:20;default:active;index" json:"status"` // active进行中 settled已结算 cancelled已取消
+	StartAt      time.Time              `json:"startAt"`
+	EndAt        time.Time              `gorm:"index" json:"endAt"`
+	SettledAt    *time.Time             `json:"settledAt"`
+	Participants []ChallengeParticipant `gorm:"foreignKey:ChallengeID" json:"participants,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+}
+
+// TableName 表名
+func (Challenge) TableName() string {
+	return "challenge"
+}
+
+// ChallengeParticipant 挑战参与者, 发起人创建时即为已接受
+type ChallengeParticipant struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ChallengeID uint       `gorm:"uniqueIndex:idx_challenge_user;not null" json:"challengeId"`
+	UserID      uint       `gorm:"uniqueIndex:idx_challenge_user;index;not null" json:"userId"`
+	User        *SysUser   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Status      string     `gorm:"size:20;default:invited" json:"status"` // invited待接受 accepted已接受 declined已拒绝 expired已过期
+	Staked      int        `gorm:"default:0" json:"staked"`               // 已托管的押注
+	Progress    int        `gorm:"default:0" json:"progress"`             // 结算时的完成次数
+	IsWinner    bool       `gorm:"default:false" json:"isWinner"`
+	Payout      int        `gorm:"default:0" json:"payout"` // 结算获得的金币
+	RespondedAt *time.Time `json:"respondedAt"`
+}
+
+// TableName 表名
+func (ChallengeParticipant) TableName() string {
+	return "challenge_participant"
+}
@@ -0,0 +1,221 @@
+// Package models 经济系统模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Currency 币种
+type Currency struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Code      string    `gorm:"size:20;uniqueIndex;not null" json:"code"` // gold/gem/token
+	Name      string    `gorm:"size:50;not null" json:"name"`
+	Icon      string    `gorm:"size:50" json:"icon"`
+	IsActive  bool      `gorm:"default:true" json:"isActive"`
+	Sort      int       `gorm:"default:0" json:"sort"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (Currency) TableName() string {
+	return "currency"
+}
+
+// UserWallet 用户钱包, 保存金币以外币种的余额
+type UserWallet struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_wallet_user_currency;not null" json:"userId"`
+	Currency  string    `gorm:"size:20;uniqueIndex:idx_wallet_user_currency;not null" json:"currency"`
+	Balance   int       `gorm:"default:0" json:"balance"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (UserWallet) TableName() string {
+	return "user_wallet"
+}
+
+// TaskCurrencyReward 任务额外币种奖励
+type TaskCurrencyReward struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	TaskID   uint   `gorm:"index;not null" json:"taskId"`
+	Currency string `gorm:"size:20;not null" json:"currency"`
+	Amount   int    `gorm:"not null" json:"amount"`
+}
+
+// TableName 表名
+func (TaskCurrencyReward) TableName() string {
+	return "task_currency_reward"
+}
+
+// SavingsGoal 心愿储蓄目标
+type SavingsGoal struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"index;not null" json:"userId"`
+	RewardID    uint      `gorm:"index;not null" json:"rewardId"`
+	Reward      *Reward   `gorm:"foreignKey:RewardID" json:"reward,omitempty"`
+	Currency    string    `gorm:"size:20;default:gold" json:"currency"` // 储蓄币种, 与奖励计价币种一致
+	Saved       int       `gorm:"default:0" json:"saved"`
+	AutoPercent int       `gorm:"default:0" json:"autoPercent"`         // 完成任务时自动存入收入的百分比
+	Status      string    `gorm:"size:20;default:active" json:"status"` // active进行中 purchased已兑换 cancelled已取消
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (SavingsGoal) TableName() string {
+	return "savings_goal"
+}
+
+// BankProduct 定期存款产品
+type BankProduct struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"size:50;not null" json:"name"`
+	Rate        float64        `gorm:"type:decimal(6,2);default:0" json:"rate"`        // 整期利率(%)
+	TermDays    int            `gorm:"not null" json:"termDays"`                       // 存期天数
+	MinDeposit  int            `gorm:"default:1" json:"minDeposit"`                    // 单笔最低存入
+	MaxDeposit  int            `gorm:"default:0" json:"maxDeposit"`                    // 每人在存本金上限, 0不限
+	PenaltyRate float64        `gorm:"type:decimal(6,2);default:0" json:"penaltyRate"` // 提前支取罚金(%), 按本金计算
+	IsActive    bool           `gorm:"default:true" json:"isActive"`
+	Sort        int            `gorm:"default:0" json:"sort"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (BankProduct) TableName() string {
+	return "bank_product"
+}
+
+// BankDeposit 定期存款记录, 利率和罚金比例在存入时固定
+type BankDeposit struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	TenantID    uint         `gorm:"default:1;index" json:"tenantId"` // 所属空间, 与用户一致
+	UserID      uint         `gorm:"index;not null" json:"userId"`
+	ProductID   uint         `gorm:"index;not null" json:"productId"`
+	Product     *BankProduct `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Principal   int          `gorm:"not null" json:"principal"`
+	Rate        float64      `gorm:"type:decimal(6,2)" json:"rate"`
+	PenaltyRate float64      `gorm:"type:decimal(6,2)" json:"penaltyRate"`
+	Interest    int          `gorm:"default:0" json:"interest"`                  // 到期利息
+	Penalty     int          `gorm:"default:0" json:"penalty"`                   // 提前支取罚金
+	Status      string       `gorm:"size:20;default:active;index" json:"status"` // active存款中 matured已到期 withdrawn提前支取
+	StartAt     time.Time    `json:"startAt"`
+	MaturityAt  time.Time    `gorm:"index" json:"maturityAt"`
+	SettledAt   *time.Time   `json:"settledAt"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// TableName 表名
+func (BankDeposit) TableName() string {
+	return "bank_deposit"
+}
+
+// Transfer 用户间转账/赠礼记录, 双方流水通过 RefID 关联到本记录
+type Transfer struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"default:1;index" json:"tenantId"` // 所属空间, 仅允许同空间用户之间转账
+	FromUserID uint      `gorm:"index;not null" json:"fromUserId"`
+	FromUser   *SysUser  `gorm:"foreignKey:FromUserID" json:"fromUser,omitempty"`
+	ToUserID   uint      `gorm:"index;not null" json:"toUserId"`
+	ToUser     *SysUser  `gorm:"foreignKey:ToUserID" json:"toUser,omitempty"`
+	Type       string    `gorm:"size:20;not null" json:"type"` // gold转账 gift赠礼
+	Currency   string    `gorm:"size:20;default:gold" json:"currency"`
+	Amount     int       `gorm:"not null" json:"amount"` // 转账金额或礼物价格
+	RewardID   uint      `gorm:"default:0" json:"rewardId"`
+	Reward     *Reward   `gorm:"foreignKey:RewardID" json:"reward,omitempty"`
+	Remark     string    `gorm:"size:255" json:"remark"`
+	CreatedAt  time.Time `gorm:"index" json:"createdAt"`
+}
+
+// TableName 表名
+func (Transfer) TableName() string {
+	return "transfer"
+}
+
+// SaleEvent 限时促销活动
+type SaleEvent struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  uint           `gorm:"default:1;index" json:"tenantId"` // 所属空间
+	Name      string         `gorm:"size:100;not null" json:"name"`
+	Percent   int            `gorm:"not null" json:"percent"`   // 折扣百分比, 20表示减免20%
+	Category  string         `gorm:"size:50" json:"category"`   // 适用分类, 为空不限
+	RewardID  uint           `gorm:"default:0" json:"rewardId"` // 适用奖励, 0不限
+	StartAt   time.Time      `gorm:"index" json:"startAt"`
+	EndAt     time.Time      `gorm:"index" json:"endAt"`
+	IsActive  bool           `gorm:"default:true" json:"isActive"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (SaleEvent) TableName() string {
+	return "sale_event"
+}
+
+// Coupon 优惠券码
+type Coupon struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	TenantID     uint           `gorm:"default:1;uniqueIndex:idx_coupon_tenant_code" json:"tenantId"`    // 所属空间
+	Code         string         `gorm:"size:50;uniqueIndex:idx_coupon_tenant_code;not null" json:"code"` // 空间内唯一
+	Name         string         `gorm:"size:100" json:"name"`
+	Type         string         `gorm:"size:20;default:percent" json:"type"` // percent按比例 amount固定减免
+	Value        int            `gorm:"not null" json:"value"`               // 百分比或减免数量
+	MinCost      int            `gorm:"default:0" json:"minCost"`            // 最低使用价格(促销后)
+	Category     string         `gorm:"size:50" json:"category"`             // 适用分类, 为空不限
+	MaxUses      int            `gorm:"default:0" json:"maxUses"`            // 总使用次数, 1为单次券 0不限
+	PerUserLimit int            `gorm:"default:0" json:"perUserLimit"`       // 每人使用次数, 0不限
+	UsedCount    int            `gorm:"default:0" json:"usedCount"`
+	StartAt      *time.Time     `json:"startAt"`
+	EndAt        *time.Time     `json:"endAt"`
+	IsActive     bool           `gorm:"default:true" json:"isActive"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Coupon) TableName() string {
+	return "coupon"
+}
+
+// CouponRedemption 优惠券使用记录
+type CouponRedemption struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CouponID  uint      `gorm:"index;not null" json:"couponId"`
+	UserID    uint      `gorm:"index;not null" json:"userId"`
+	RewardID  uint      `gorm:"not null" json:"rewardId"`
+	LogID     uint      `gorm:"index;default:0" json:"logId"` // 兑换扣款流水, 退款时据此撤销使用记录
+	Discount  int       `json:"discount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (CouponRedemption) TableName() string {
+	return "coupon_redemption"
+}
+
+// EarningCap 每日收益上限, 限制单个用户每天通过任务获得的金币和经验
+type EarningCap struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Category        string    `gorm:"size:50;uniqueIndex" json:"category"`     // 任务分类, 为空表示全部任务合计
+	MaxGold         int       `gorm:"default:0" json:"maxGold"`                // 0不限
+	MaxExp          int       `gorm:"default:0" json:"maxExp"`                 // 0不限
+	Overflow        string    `gorm:"size:20;default:discard" json:"overflow"` // discard丢弃 convert转换
+	ConvertCurrency string    `gorm:"size:20" json:"convertCurrency"`          // 溢出部分转换的币种
+	ConvertPercent  int       `gorm:"default:0" json:"convertPercent"`         // 溢出部分按百分比转换
+	IsActive        bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (EarningCap) TableName() string {
+	return "earning_cap"
+}
@@ -9,19 +9,23 @@ import (
 
 // Task 任务
 type Task struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"size:100;not null" json:"title"`
-	Description string         `gorm:"size:500" json:"description"`
-	GoldReward  int            `gorm:"default:0" json:"goldReward"`
-	ExpReward   int            `gorm:"default:0" json:"expReward"`
-	Type        string         `gorm:"size:20;default:daily" json:"type"` // daily每日 once一次性
-	Category    string         `gorm:"size:50" json:"category"`
-	Icon        string         `gorm:"size:50" json:"icon"`
-	IsActive    bool           `gorm:"default:true" json:"isActive"`
-	Sort        int            `gorm:"default:0" json:"sort"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uint                 `gorm:"primaryKey" json:"id"`
+	Title           string               `gorm:"size:100;not null" json:"title"`
+	Description     string               `gorm:"size:500" json:"description"`
+	GoldReward      int                  `gorm:"default:0" json:"goldReward"`
+	ExpReward       int                  `gorm:"default:0" json:"expReward"`
+	Type            string               `gorm:"size:20;default:daily" json:"type"` // daily每日 once一次性
+	Category        string               `gorm:"size:50" json:"category"`
+	TenantID        uint                 `gorm:"default:1;index" json:"tenantId"`      // 所属空间
+	HouseholdID     uint                 `gorm:"default:0;index" json:"householdId"`   // 所属家庭, 0为全站任务
+	RequireApproval bool                 `gorm:"default:false" json:"requireApproval"` // 完成后需监护人审核才发放奖励
+	Icon            string               `gorm:"size:50" json:"icon"`
+	IsActive        bool                 `gorm:"default:true" json:"isActive"`
+	Sort            int                  `gorm:"default:0" json:"sort"`
+	CurrencyRewards []TaskCurrencyReward `gorm:"foreignKey:TaskID" json:"currencyRewards,omitempty"` // 额外币种奖励
+	CreatedAt       time.Time            `json:"createdAt"`
+	UpdatedAt       time.Time            `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt       `gorm:"index" json:"-"`
 }
 
 // TableName 表名
@@ -31,11 +35,15 @@ func (Task) TableName() string {
 
 // UserTask 用户任务完成记录
 type UserTask struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	UserID      uint      `gorm:"index;not null" json:"userId"`
-	TaskID      uint      `gorm:"index;not null" json:"taskId"`
-	Task        *Task     `gorm:"foreignKey:TaskID" json:"task,omitempty"`
-	CompletedAt time.Time `json:"completedAt"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index;not null" json:"userId"`
+	User        *SysUser   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TaskID      uint       `gorm:"index;not null" json:"taskId"`
+	Task        *Task      `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	Status      string     `gorm:"size:20;default:approved;index" json:"status"` // approved已完成 pending待审核 rejected已驳回
+	ReviewerID  uint       `gorm:"default:0" json:"reviewerId"`
+	ReviewedAt  *time.Time `json:"reviewedAt"`
+	CompletedAt time.Time  `json:"completedAt"`
 }
 
 // TableName 表名
@@ -49,9 +57,13 @@ type Reward struct {
 	Title       string         `gorm:"size:100;not null" json:"title"`
 	Description string         `gorm:"size:500" json:"description"`
 	Cost        int            `gorm:"default:0" json:"cost"`
-	Stock       int            `gorm:"default:-1" json:"stock"` // -1无限
+	Currency    string         `gorm:"size:20;default:gold" json:"currency"` // 计价币种
+	Type        string         `gorm:"size:20;default:item" json:"type"`     // item普通奖励 chest宝箱
+	Stock       int            `gorm:"default:-1" json:"stock"`              // -1无限
 	Image       string         `gorm:"size:255" json:"image"`
 	Category    string         `gorm:"size:50" json:"category"`
+	TenantID    uint           `gorm:"default:1;index" json:"tenantId"`    // 所属空间
+	HouseholdID uint           `gorm:"default:0;index" json:"householdId"` // 所属家庭, 0为全站奖励
 	IsActive    bool           `gorm:"default:true" json:"isActive"`
 	Sort        int            `gorm:"default:0" json:"sort"`
 	CreatedAt   time.Time      `json:"createdAt"`
@@ -68,12 +80,15 @@ func (Reward) TableName() string {
 type UserLog struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	UserID      uint      `gorm:"index;not null" json:"userId"`
-	Type        string    `gorm:"size:20;not null" json:"type"` // gold_in/gold_out/exp_in
+	TenantID    uint      `gorm:"default:1;index" json:"tenantId"`            // 所属空间, 与用户一致
+	Currency    string    `gorm:"size:20;default:gold;index" json:"currency"` // 币种代码, 经验流水为exp
+	Type        string    `gorm:"size:30;not null" json:"type"`               // <币种>_in/<币种>_out, 如 gold_in/gem_out/exp_in
 	Amount      int       `gorm:"not null" json:"amount"`
 	Balance     int       `json:"balance"`
 	Description string    `gorm:"size:255" json:"description"`
-	RefType     string    `gorm:"size:50" json:"refType"` // task/reward/admin
+	RefType     string    `gorm:"size:50" json:"refType"` // task/reward/admin/refund
 	RefID       uint      `json:"refId"`
+	OperatorID  uint      `gorm:"default:0" json:"operatorId"` // 操作管理员ID, 0表示系统
 	CreatedAt   time.Time `json:"createdAt"`
 }
 
@@ -82,12 +97,71 @@ func (UserLog) TableName() string {
 	return "user_log"
 }
 
+// ChestDrop 宝箱掉落表条目
+type ChestDrop struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	ChestID      uint   `gorm:"index;not null" json:"chestId"` // 宝箱奖励ID
+	Name         string `gorm:"size:100;not null" json:"name"`
+	Kind         string `gorm:"size:20;not null" json:"kind"`         // currency货币 exp经验 reward奖励 item道具 badge徽章
+	Currency     string `gorm:"size:20" json:"currency"`              // kind=currency 时的币种
+	Amount       int    `gorm:"default:0" json:"amount"`              // 货币/经验数量
+	ItemRewardID uint   `gorm:"default:0" json:"itemRewardId"`        // kind=reward 时发放的奖励
+	Rarity       string `gorm:"size:20;default:common" json:"rarity"` // common/rare/epic/legendary
+	Weight       int    `gorm:"not null" json:"weight"`
+	Sort         int    `gorm:"default:0" json:"sort"`
+}
+
+// TableName 表名
+func (ChestDrop) TableName() string {
+	return "chest_drop"
+}
+
+// ChestOpen 开箱记录, 保存随机种子与当时的掉落表快照以便事后验证
+type ChestOpen struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"index;not null" json:"userId"`
+	ChestID     uint      `gorm:"index;not null" json:"chestId"`
+	Seed        string    `gorm:"size:64;not null" json:"seed"`  // 服务端随机种子(hex)
+	Nonce       string    `gorm:"size:64;not null" json:"nonce"` // 参与哈希的随机数
+	Roll        uint64    `json:"roll"`                          // 哈希结果对总权重取模
+	TotalWeight int       `json:"totalWeight"`
+	DropID      uint      `json:"dropId"`
+	DropName    string    `gorm:"size:100" json:"dropName"`
+	Rarity      string    `gorm:"size:20" json:"rarity"`
+	DropTable   string    `gorm:"type:text" json:"dropTable"` // 掉落表快照(JSON)
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (ChestOpen) TableName() string {
+	return "chest_open"
+}
+
+// UserItem 用户背包物品
+type UserItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"userId"`
+	Kind      string    `gorm:"size:20;not null" json:"kind"` // reward奖励 item道具 badge徽章
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	RewardID  uint      `gorm:"default:0" json:"rewardId"`
+	Rarity    string    `gorm:"size:20" json:"rarity"`
+	Source    string    `gorm:"size:20" json:"source"` // 来源: chest/checkin/season/gift
+	SourceID  uint      `json:"sourceId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (UserItem) TableName() string {
+	return "user_item"
+}
+
 // Announcement 公告
 type Announcement struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	Title     string         `gorm:"size:100;not null" json:"title"`
 	Content   string         `gorm:"type:text" json:"content"`
 	Type      string         `gorm:"size:20;default:notice" json:"type"` // notice/activity/update
+	TenantID  uint           `gorm:"default:1;index" json:"tenantId"`    // 所属空间
 	IsActive  bool           `gorm:"default:true" json:"isActive"`
 	Sort      int            `gorm:"default:0" json:"sort"`
 	CreatedAt time.Time      `json:"createdAt"`
@@ -103,6 +177,7 @@ func (Announcement) TableName() string {
 // ThemeConfig H5主题配置
 type ThemeConfig struct {
 	ID             uint      `gorm:"primaryKey" json:"id"`
+	TenantID       uint      `gorm:"default:1;uniqueIndex" json:"tenantId"` // 所属空间, 每个空间一份配置
 	PrimaryColor   string    `gorm:"size:20;default:#1989fa" json:"primaryColor"`
 	SecondaryColor string    `gorm:"size:20;default:#ff976a" json:"secondaryColor"`
 	GoldColor      string    `gorm:"size:20;default:#ffd700" json:"goldColor"`
@@ -116,3 +191,47 @@ type ThemeConfig struct {
 func (ThemeConfig) TableName() string {
 	return "theme_config"
 }
+
+// RewardBundle 组合奖励: 金币、经验、额外币种和背包物品, 用于签到日历和赛季档位
+type RewardBundle struct {
+	Gold       int    `gorm:"default:0" json:"gold"`
+	Exp        int    `gorm:"default:0" json:"exp"`
+	Currency   string `gorm:"size:20" json:"currency"` // 额外币种奖励
+	Amount     int    `gorm:"default:0" json:"amount"`
+	ItemName   string `gorm:"size:100" json:"itemName"` // 特殊物品, 发放到背包
+	ItemKind   string `gorm:"size:20" json:"itemKind"`  // item道具 badge徽章
+	ItemRarity string `gorm:"size:20" json:"itemRarity"`
+}
+
+// CheckinReward 签到日历奖励, Day 为当月第几次签到
+type CheckinReward struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	Day          int  `gorm:"uniqueIndex;not null" json:"day"` // 1-31
+	RewardBundle `gorm:"embedded"`
+}
+
+// TableName 表名
+func (CheckinReward) TableName() string {
+	return "checkin_reward"
+}
+
+// UserCheckin 用户签到记录
+type UserCheckin struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_checkin_user_date;index:idx_checkin_user_month;not null" json:"userId"`
+	Date      string    `gorm:"size:10;uniqueIndex:idx_checkin_user_date;not null" json:"date"` // 2006-01-02
+	Month     string    `gorm:"size:7;index:idx_checkin_user_month;not null" json:"month"`      // 2006-01
+	Seq       int       `json:"seq"`                                                            // 当月第几次签到
+	IsMakeup  bool      `gorm:"default:false" json:"isMakeup"`
+	Gold      int       `json:"gold"`
+	Exp       int       `json:"exp"`
+	Currency  string    `gorm:"size:20" json:"currency"`
+	Amount    int       `json:"amount"`
+	ItemName  string    `gorm:"size:100" json:"itemName"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (UserCheckin) TableName() string {
+	return "user_checkin"
+}
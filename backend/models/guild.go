@@ -0,0 +1,69 @@
+// Package models 公会模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Guild 公会
+type Guild struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	TenantID    uint           `gorm:"default:1;uniqueIndex:idx_guild_tenant_name" json:"tenantId"`    // 所属空间
+	Name        string         `gorm:"size:50;uniqueIndex:idx_guild_tenant_name;not null" json:"name"` // 空间内唯一
+	Description string         `gorm:"size:255" json:"description"`
+	Icon        string         `gorm:"size:50" json:"icon"`
+	LeaderID    uint           `gorm:"index;not null" json:"leaderId"`
+	Leader      *SysUser       `gorm:"foreignKey:LeaderID" json:"leader,omitempty"`
+	IsOpen      bool           `gorm:"default:true" json:"isOpen"` // 是否允许自由加入
+	MemberLimit int            `gorm:"default:30" json:"memberLimit"`
+	MemberCount int            `gorm:"default:0" json:"memberCount"`
+	GoalTarget  int            `gorm:"default:50" json:"goalTarget"` // 每周目标: 全员累计完成任务数
+	GoalGold    int            `gorm:"default:0" json:"goalGold"`    // 达成后每位成员获得的金币
+	GoalExp     int            `gorm:"default:0" json:"goalExp"`     // 达成后每位成员获得的经验
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Guild) TableName() string {
+	return "guild"
+}
+
+// GuildMember 公会成员, 每个用户同时只能加入一个公会
+type GuildMember struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	GuildID  uint      `gorm:"index;not null" json:"guildId"`
+	UserID   uint      `gorm:"uniqueIndex;not null" json:"userId"`
+	User     *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Role     string    `gorm:"size:20;default:member" json:"role"` // leader会长 officer官员 member成员
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// TableName 表名
+func (GuildMember) TableName() string {
+	return "guild_member"
+}
+
+// GuildWeek 公会周目标进度, 目标与奖励在本周首次产生进度时从公会设置快照
+type GuildWeek struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GuildID     uint       `gorm:"uniqueIndex:idx_guild_week;not null" json:"guildId"`
+	Week        string     `gorm:"size:10;uniqueIndex:idx_guild_week;not null" json:"week"` // 2006-W01
+	Target      int        `json:"target"`
+	Progress    int        `gorm:"default:0" json:"progress"`
+	Gold        int        `json:"gold"`
+	Exp         int        `json:"exp"`
+	CompletedAt *time.Time `json:"completedAt"`
+	PaidAt      *time.Time `json:"paidAt"`                     // 奖励结算时间, 达成后由定时任务发放
+	PaidCount   int        `gorm:"default:0" json:"paidCount"` // 获得奖励的成员数
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// TableName 表名
+func (GuildWeek) TableName() string {
+	return "guild_week"
+}
@@ -0,0 +1,39 @@
+// Package models 家庭模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Household 家庭, 由监护人创建并管理家庭成员专属的任务和奖励
+type Household struct {
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	Name      string            `gorm:"size:50;not null" json:"name"`
+	OwnerID   uint              `gorm:"uniqueIndex;not null" json:"ownerId"` // 创建家庭的监护人
+	Members   []HouseholdMember `gorm:"foreignKey:HouseholdID" json:"members,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Household) TableName() string {
+	return "household"
+}
+
+// HouseholdMember 家庭成员, 每个用户只能属于一个家庭
+type HouseholdMember struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	HouseholdID uint      `gorm:"index;not null" json:"householdId"`
+	UserID      uint      `gorm:"uniqueIndex;not null" json:"userId"`
+	User        *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Role        string    `gorm:"size:20;default:child" json:"role"` // guardian监护人 child孩子
+	JoinedAt    time.Time `json:"joinedAt"`
+}
+
+// TableName 表名
+func (HouseholdMember) TableName() string {
+	return "household_member"
+}
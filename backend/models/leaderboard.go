@@ -0,0 +1,22 @@
+// Package models 排行榜模型
+package models
+
+import "time"
+
+// LeaderboardEntry 排行榜快照, 由定时任务按指标和周期预先计算
+type LeaderboardEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Metric    string    `gorm:"size:20;uniqueIndex:idx_leaderboard_entry;index:idx_leaderboard_rank;not null" json:"metric"`    // exp经验 tasks任务数 streak连续天数
+	Period    string    `gorm:"size:20;uniqueIndex:idx_leaderboard_entry;index:idx_leaderboard_rank;not null" json:"period"`    // daily/weekly/monthly/all
+	PeriodKey string    `gorm:"size:10;uniqueIndex:idx_leaderboard_entry;index:idx_leaderboard_rank;not null" json:"periodKey"` // 2006-01-02 / 2006-W01 / 2006-01 / all
+	UserID    uint      `gorm:"uniqueIndex:idx_leaderboard_entry;not null" json:"userId"`
+	User      *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Value     int       `gorm:"index:idx_leaderboard_rank" json:"value"`
+	Rank      int       `json:"rank"` // 全站排名
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (LeaderboardEntry) TableName() string {
+	return "leaderboard_entry"
+}
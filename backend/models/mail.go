@@ -0,0 +1,58 @@
+// Package models 邮件模型
+package models
+
+import "time"
+
+// EmailMessage 邮件发送队列, 同时作为投递记录保留
+type EmailMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TenantID      uint       `gorm:"default:1;index" json:"tenantId"` // 所属空间, 与收件用户一致
+	UserID        uint       `gorm:"index;default:0" json:"userId"`   // 收件用户, 0表示非用户邮件 (如测试邮件)
+	To            string     `gorm:"size:100;not null" json:"to"`
+	Template      string     `gorm:"size:30;index" json:"template"` // password_reset/weekly_summary/reminder ...
+	Subject       string     `gorm:"size:200;not null" json:"subject"`
+	Body          string     `gorm:"type:text" json:"body,omitempty"`
+	Status        string     `gorm:"size:20;default:pending;index:idx_email_queue" json:"status"` // pending待发送 sent已发送 failed失败
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	LastError     string     `gorm:"size:500" json:"lastError"`
+	NextAttemptAt time.Time  `gorm:"index:idx_email_queue" json:"nextAttemptAt"`
+	SentAt        *time.Time `json:"sentAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TableName 表名
+func (EmailMessage) TableName() string {
+	return "email_message"
+}
+
+// PasswordReset 找回密码令牌, 仅保存令牌的哈希
+type PasswordReset struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"userId"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName 表名
+func (PasswordReset) TableName() string {
+	return "password_reset"
+}
+
+// EmailVerification 修改邮箱的验证令牌, 验证通过后新邮箱才生效
+type EmailVerification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"userId"`
+	Email     string     `gorm:"size:100;not null" json:"email"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName 表名
+func (EmailVerification) TableName() string {
+	return "email_verification"
+}
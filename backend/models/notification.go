@@ -0,0 +1,25 @@
+// Package models 站内通知模型
+package models
+
+import "time"
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index:idx_notification_user;not null" json:"userId"`
+	Type      string     `gorm:"size:30;not null" json:"type"` // partner_invite/partner_missed/nudge ...
+	Title     string     `gorm:"size:100;not null" json:"title"`
+	Body      string     `gorm:"size:500" json:"body"`
+	Link      string     `gorm:"size:255" json:"link"`      // H5端跳转路径
+	SenderID  uint       `gorm:"default:0" json:"senderId"` // 发送人, 0表示系统
+	Sender    *SysUser   `gorm:"foreignKey:SenderID" json:"sender,omitempty"`
+	RefType   string     `gorm:"size:50" json:"refType"`
+	RefID     uint       `json:"refId"`
+	ReadAt    *time.Time `gorm:"index:idx_notification_user" json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName 表名
+func (Notification) TableName() string {
+	return "notification"
+}
@@ -0,0 +1,25 @@
+// Package models 任务提醒模型
+package models
+
+import "time"
+
+// TaskReminder 任务提醒: 到达设定时间时任务仍未完成则发送通知
+type TaskReminder struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"index;not null" json:"userId"`
+	TaskID       uint      `gorm:"index;not null" json:"taskId"`
+	Task         *Task     `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	RemindAt     string    `gorm:"size:5;not null" json:"remindAt"`  // 提醒时间 HH:MM, 按 Timezone 计算
+	Weekdays     string    `gorm:"size:20" json:"weekdays"`          // 生效的星期 1-7 逗号分隔, 为空表示每天
+	Timezone     string    `gorm:"size:50;not null" json:"timezone"` // IANA时区, 如 Asia/Shanghai
+	Channels     string    `gorm:"size:100" json:"channels"`         // 站内通知之外的发送渠道, 逗号分隔, 如 email,webhook
+	IsActive     bool      `gorm:"default:true" json:"isActive"`
+	LastSentDate string    `gorm:"size:10;index" json:"lastSentDate"` // 最近一次检查的当地日期, 每天最多提醒一次
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (TaskReminder) TableName() string {
+	return "task_reminder"
+}
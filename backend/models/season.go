@@ -0,0 +1,79 @@
+// Package models 赛季通行证模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Season 赛季
+type Season struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Name            string         `gorm:"size:50;not null" json:"name"`
+	Description     string         `gorm:"size:255" json:"description"`
+	StartAt         time.Time      `gorm:"not null" json:"startAt"`
+	EndAt           time.Time      `gorm:"not null" json:"endAt"`
+	PointPercent    int            `gorm:"default:100" json:"pointPercent"`            // 完成任务获得赛季积分 = 任务经验 * 百分比
+	PremiumCurrency string         `gorm:"size:20;default:gem" json:"premiumCurrency"` // 高级通行证计价币种
+	PremiumPrice    int            `gorm:"default:0" json:"premiumPrice"`
+	ArchivedAt      *time.Time     `json:"archivedAt"` // 赛季结束归档时间
+	Tiers           []SeasonTier   `gorm:"foreignKey:SeasonID" json:"tiers,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Season) TableName() string {
+	return "season"
+}
+
+// SeasonTier 赛季奖励档位
+type SeasonTier struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	SeasonID     uint   `gorm:"index;not null" json:"seasonId"`
+	Level        int    `gorm:"not null" json:"level"`             // 档位等级
+	Points       int    `gorm:"not null" json:"points"`            // 解锁所需累计积分
+	Track        string `gorm:"size:20;default:free" json:"track"` // free免费 premium高级
+	RewardBundle `gorm:"embedded"`
+}
+
+// TableName 表名
+func (SeasonTier) TableName() string {
+	return "season_tier"
+}
+
+// UserSeason 用户赛季进度
+type UserSeason struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"uniqueIndex:idx_user_season;not null" json:"userId"`
+	SeasonID  uint       `gorm:"uniqueIndex:idx_user_season;not null" json:"seasonId"`
+	Season    *Season    `gorm:"foreignKey:SeasonID" json:"season,omitempty"`
+	Points    int        `gorm:"default:0" json:"points"`
+	Premium   bool       `gorm:"default:false" json:"premium"`
+	PremiumAt *time.Time `json:"premiumAt"`
+	FinalTier int        `gorm:"default:0" json:"finalTier"` // 归档时达到的档位等级
+	Archived  bool       `gorm:"default:false" json:"archived"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TableName 表名
+func (UserSeason) TableName() string {
+	return "user_season"
+}
+
+// SeasonClaim 赛季档位领取记录
+type SeasonClaim struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_season_claim;not null" json:"userId"`
+	TierID    uint      `gorm:"uniqueIndex:idx_season_claim;not null" json:"tierId"`
+	SeasonID  uint      `gorm:"index;not null" json:"seasonId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (SeasonClaim) TableName() string {
+	return "season_claim"
+}
@@ -0,0 +1,142 @@
+// Package models 社交关系模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Friendship 好友关系, 由 UserID 向 FriendID 发起申请
+type Friendship struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"uniqueIndex:idx_friendship_pair;not null" json:"userId"`         // 申请人
+	FriendID   uint       `gorm:"uniqueIndex:idx_friendship_pair;index;not null" json:"friendId"` // 被申请人
+	User       *SysUser   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Friend     *SysUser   `gorm:"foreignKey:FriendID" json:"friend,omitempty"`
+	Status     string     `gorm:"size:20;default:pending;index" json:"status"` // pending待处理 accepted已成为好友
+	Message    string     `gorm:"size:100" json:"message"`
+	AcceptedAt *time.Time `json:"acceptedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// TableName 表名
+func (Friendship) TableName() string {
+	return "friendship"
+}
+
+// UserBlock 拉黑记录
+type UserBlock struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_block_pair;not null" json:"userId"`
+	BlockedID uint      `gorm:"uniqueIndex:idx_block_pair;index;not null" json:"blockedId"`
+	Blocked   *SysUser  `gorm:"foreignKey:BlockedID" json:"blocked,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (UserBlock) TableName() string {
+	return "user_block"
+}
+
+// UserPrivacy 隐私设置, 可见范围: public所有人 friends仅好友 private仅自己
+type UserPrivacy struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"uniqueIndex;not null" json:"userId"`
+	Level         string    `gorm:"size:20;default:public" json:"level"`   // 等级与经验
+	Streak        string    `gorm:"size:20;default:friends" json:"streak"` // 连续打卡天数
+	Logs          string    `gorm:"size:20;default:private" json:"logs"`   // 流水记录
+	Feed          string    `gorm:"size:20;default:friends" json:"feed"`   // 动态
+	AllowRequests bool      `gorm:"default:true" json:"allowRequests"`     // 是否接受好友申请
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (UserPrivacy) TableName() string {
+	return "user_privacy"
+}
+
+// FeedEvent 动态, 由任务完成、升级、成就和兑换等业务流程发布
+type FeedEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TenantID     uint      `gorm:"default:1;index" json:"tenantId"` // 所属空间, 与发布者一致
+	UserID       uint      `gorm:"index;not null" json:"userId"`
+	User         *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Kind         string    `gorm:"size:20;not null" json:"kind"` // task完成任务 level_up升级 achievement成就 purchase兑换
+	Title        string    `gorm:"size:255;not null" json:"title"`
+	RefType      string    `gorm:"size:50" json:"refType"`
+	RefID        uint      `json:"refId"`
+	CheerCount   int       `gorm:"default:0" json:"cheerCount"`
+	CommentCount int       `gorm:"default:0" json:"commentCount"`
+	CreatedAt    time.Time `gorm:"index" json:"createdAt"`
+}
+
+// TableName 表名
+func (FeedEvent) TableName() string {
+	return "feed_event"
+}
+
+// FeedReaction 动态点赞(加油)
+type FeedReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventID   uint      `gorm:"uniqueIndex:idx_feed_reaction;not null" json:"eventId"`
+	UserID    uint      `gorm:"uniqueIndex:idx_feed_reaction;not null" json:"userId"`
+	User      *SysUser  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Kind      string    `gorm:"size:20;default:cheer" json:"kind"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 表名
+func (FeedReaction) TableName() string {
+	return "feed_reaction"
+}
+
+// FeedComment 动态评论
+type FeedComment struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	EventID   uint           `gorm:"index;not null" json:"eventId"`
+	UserID    uint           `gorm:"index;not null" json:"userId"`
+	User      *SysUser       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Content   string         `gorm:"size:500;not null" json:"content"`
+	CreatedAt time.Time      `json:"createdAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (FeedComment) TableName() string {
+	return "feed_comment"
+}
+
+// Accountability 监督伙伴关系, 每个用户同时只有一位监督伙伴
+type Accountability struct {
+	ID              uint                 `gorm:"primaryKey" json:"id"`
+	UserID          uint                 `gorm:"uniqueIndex;not null" json:"userId"` // 被监督人
+	User            *SysUser             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	PartnerID       uint                 `gorm:"index;not null" json:"partnerId"` // 监督伙伴
+	Partner         *SysUser             `gorm:"foreignKey:PartnerID" json:"partner,omitempty"`
+	Status          string               `gorm:"size:20;default:pending" json:"status"` // pending待接受 accepted已接受
+	LastCheckedDate string               `gorm:"size:10" json:"lastCheckedDate"`        // 最近一次日终检查的日期
+	Tasks           []AccountabilityTask `gorm:"foreignKey:AccountabilityID" json:"tasks,omitempty"`
+	AcceptedAt      *time.Time           `json:"acceptedAt"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	UpdatedAt       time.Time            `json:"updatedAt"`
+}
+
+// TableName 表名
+func (Accountability) TableName() string {
+	return "accountability"
+}
+
+// AccountabilityTask 被监督的每日任务
+type AccountabilityTask struct {
+	ID               uint  `gorm:"primaryKey" json:"id"`
+	AccountabilityID uint  `gorm:"index;not null" json:"accountabilityId"`
+	TaskID           uint  `gorm:"not null" json:"taskId"`
+	Task             *Task `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+}
+
+// TableName 表名
+func (AccountabilityTask) TableName() string {
+	return "accountability_task"
+}
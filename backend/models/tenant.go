@@ -0,0 +1,24 @@
+// Package models 空间(租户)模型
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tenant 空间, 同一部署下按部门或家庭隔离的用户和数据
+type Tenant struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Code      string         `gorm:"size:50;uniqueIndex;not null" json:"code"` // 注册和H5访问时使用的空间标识
+	Name      string         `gorm:"size:100;not null" json:"name"`
+	IsActive  bool           `gorm:"default:true" json:"isActive"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 表名
+func (Tenant) TableName() string {
+	return "tenant"
+}
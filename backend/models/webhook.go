@@ -0,0 +1,47 @@
+// Package models Webhook模型
+package models
+
+import "time"
+
+// Webhook 事件订阅, 事件发生时向 URL 推送签名的JSON
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"default:1;index" json:"tenantId"`
+	UserID    uint      `gorm:"index;default:0" json:"userId"` // 订阅的用户, 0表示空间级订阅, 接收空间内所有用户的事件
+	Name      string    `gorm:"size:50" json:"name"`
+	URL       string    `gorm:"size:500;not null" json:"url"`
+	Secret    string    `gorm:"size:64;not null" json:"secret"`  // 签名密钥
+	Events    string    `gorm:"size:200;not null" json:"events"` // 订阅的事件, 逗号分隔, 如 task.completed,user.level_up
+	IsActive  bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName 表名
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+// WebhookDelivery Webhook投递记录, 同时作为重试队列
+type WebhookDelivery struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	WebhookID     uint       `gorm:"index;not null" json:"webhookId"`
+	Webhook       *Webhook   `gorm:"foreignKey:WebhookID" json:"-"`
+	Event         string     `gorm:"size:50;not null" json:"event"`
+	Payload       string     `gorm:"type:text" json:"payload"`
+	Status        string     `gorm:"size:20;default:pending;index:idx_webhook_queue" json:"status"` // pending待投递 success成功 failed失败
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	ResponseCode  int        `json:"responseCode"`
+	ResponseBody  string     `gorm:"size:500" json:"responseBody"`
+	LastError     string     `gorm:"size:500" json:"lastError"`
+	Duration      int        `json:"duration"` // 最近一次请求耗时(毫秒)
+	NextAttemptAt time.Time  `gorm:"index:idx_webhook_queue" json:"nextAttemptAt"`
+	DeliveredAt   *time.Time `json:"deliveredAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TableName 表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
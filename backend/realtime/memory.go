@@ -0,0 +1,95 @@
+package realtime
+
+import "sync"
+
+// subscriberBuffer 每个订阅的事件缓冲, 客户端消费过慢时丢弃新事件
+const subscriberBuffer = 32
+
+// MemoryBroker 进程内发布订阅, 仅适用于单实例部署
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[string]map[*memorySubscription]struct{}
+}
+
+// NewMemoryBroker 创建进程内发布订阅
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[string]map[*memorySubscription]struct{}{}}
+}
+
+// Publish 向频道的所有订阅发送事件
+func (b *MemoryBroker) Publish(topic string, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs[topic] {
+		sub.send(event)
+	}
+	return nil
+}
+
+// Subscribe 订阅频道
+func (b *MemoryBroker) Subscribe(topics ...string) Subscription {
+	sub := &memorySubscription{
+		broker: b,
+		topics: topics,
+		ch:     make(chan Event, subscriberBuffer),
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		if b.subs[topic] == nil {
+			b.subs[topic] = map[*memorySubscription]struct{}{}
+		}
+		b.subs[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+// unsubscribe 移除订阅
+func (b *MemoryBroker) unsubscribe(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range sub.topics {
+		delete(b.subs[topic], sub)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+	}
+}
+
+// memorySubscription 进程内订阅
+type memorySubscription struct {
+	broker *MemoryBroker
+	topics []string
+	ch     chan Event
+	mu     sync.Mutex
+	closed bool
+}
+
+// Events 事件通道, 订阅关闭后通道关闭
+func (s *memorySubscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close 取消订阅
+func (s *memorySubscription) Close() {
+	s.broker.unsubscribe(s)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// send 非阻塞发送
+func (s *memorySubscription) send(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- event:
+	default:
+	}
+}
@@ -0,0 +1,88 @@
+// Package realtime 实时事件推送: 进程内发布订阅, 可替换为Redis等外部实现
+package realtime
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 事件类型
+const (
+	EventBalance      = "balance"      // 币种余额变动
+	EventLevel        = "level"        // 经验/等级变动
+	EventNotification = "notification" // 新通知
+	EventAnnouncement = "announcement" // 公告发布
+)
+
+// Event 推送给客户端的事件
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Subscription 订阅, 事件从 Events 读取, 使用完毕需调用 Close
+type Subscription interface {
+	Events() <-chan Event
+	Close()
+}
+
+// Broker 发布订阅实现, 多实例部署时替换为跨进程实现 (如Redis)
+type Broker interface {
+	Publish(topic string, event Event) error
+	Subscribe(topics ...string) Subscription
+}
+
+var (
+	mu     sync.RWMutex
+	broker Broker = NewMemoryBroker()
+)
+
+// SetBroker 替换发布订阅实现, 需在服务启动前调用
+func SetBroker(b Broker) {
+	mu.Lock()
+	defer mu.Unlock()
+	broker = b
+}
+
+// current 当前的发布订阅实现
+func current() Broker {
+	mu.RLock()
+	defer mu.RUnlock()
+	return broker
+}
+
+// UserTopic 用户私有频道
+func UserTopic(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// TenantTopic 空间广播频道
+func TenantTopic(tenantID uint) string {
+	return fmt.Sprintf("tenant:%d", tenantID)
+}
+
+// Publish 立即发布事件
+func Publish(topic string, event Event) error {
+	return current().Publish(topic, event)
+}
+
+// Subscribe 订阅一个或多个频道
+func Subscribe(topics ...string) Subscription {
+	return current().Subscribe(topics...)
+}
+
+// commitHook 支持提交后回调的事务连接, 由 database 包提供
+type commitHook interface {
+	AfterCommit(fn func())
+}
+
+// PublishAfterCommit 在事务提交后发布事件, 事务回滚时丢弃; 不在事务中时立即发布
+func PublishAfterCommit(tx *gorm.DB, topic string, event Event) {
+	if hook, ok := tx.Statement.ConnPool.(commitHook); ok {
+		hook.AfterCommit(func() { Publish(topic, event) })
+		return
+	}
+	Publish(topic, event)
+}
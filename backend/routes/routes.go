@@ -19,6 +19,31 @@ func SetupRoutes(r *gin.Engine) {
 	rewardCtrl := &controllers.RewardController{}
 	announcementCtrl := &controllers.AnnouncementController{}
 	dashboardCtrl := &controllers.DashboardController{}
+	ledgerCtrl := &controllers.LedgerController{}
+	currencyCtrl := &controllers.CurrencyController{}
+	goalCtrl := &controllers.GoalController{}
+	bankCtrl := &controllers.BankController{}
+	transferCtrl := &controllers.TransferController{}
+	chestCtrl := &controllers.ChestController{}
+	promotionCtrl := &controllers.PromotionController{}
+	economyCtrl := &controllers.EconomyController{}
+	checkinCtrl := &controllers.CheckinController{}
+	seasonCtrl := &controllers.SeasonController{}
+	friendCtrl := &controllers.FriendController{}
+	guildCtrl := &controllers.GuildController{}
+	bossCtrl := &controllers.BossController{}
+	leaderboardCtrl := &controllers.LeaderboardController{}
+	challengeCtrl := &controllers.ChallengeController{}
+	feedCtrl := &controllers.FeedController{}
+	accountabilityCtrl := &controllers.AccountabilityController{}
+	notificationCtrl := &controllers.NotificationController{}
+	householdCtrl := &controllers.HouseholdController{}
+	tenantCtrl := &controllers.TenantController{}
+	eventCtrl := &controllers.EventController{}
+	reminderCtrl := &controllers.ReminderController{}
+	mailCtrl := &controllers.MailController{}
+	webhookCtrl := &controllers.WebhookController{}
+	adminWebhookCtrl := &controllers.WebhookController{Admin: true}
 
 	// API 路由组
 	api := r.Group("/api")
@@ -28,11 +53,17 @@ func SetupRoutes(r *gin.Engine) {
 		{
 			auth.POST("/login", authCtrl.Login)
 			auth.POST("/register", authCtrl.Register)
+			auth.POST("/forgot-password", authCtrl.ForgotPassword)
+			auth.POST("/reset-password", authCtrl.ResetPassword)
+			auth.POST("/verify-email", authCtrl.VerifyEmail)
 		}
 
 		// 获取H5主题配置(公开)
 		api.GET("/theme", dashboardCtrl.ThemeConfig)
 
+		// 实时事件, EventSource 无法设置请求头, 单独允许通过 token 参数认证
+		api.GET("/app/events", middleware.QueryToken(), middleware.JWTAuth(), eventCtrl.Stream)
+
 		// ===== 需要认证的接口 =====
 		authenticated := api.Group("")
 		authenticated.Use(middleware.JWTAuth())
@@ -54,21 +85,16 @@ func SetupRoutes(r *gin.Engine) {
 				admin.PUT("/users/:id", userCtrl.Update)
 				admin.DELETE("/users/:id", userCtrl.Delete)
 				admin.POST("/users/:id/reset-password", userCtrl.ResetPassword)
+				admin.POST("/users/:id/adjust", userCtrl.Adjust)
+				admin.GET("/users/:id/logs", userCtrl.Logs)
 
 				// 角色管理
 				admin.GET("/roles", roleCtrl.List)
-				admin.POST("/roles", roleCtrl.Create)
-				admin.PUT("/roles/:id", roleCtrl.Update)
-				admin.DELETE("/roles/:id", roleCtrl.Delete)
 				admin.GET("/roles/:id/menus", roleCtrl.GetRoleMenus)
-				admin.POST("/roles/:id/menus", roleCtrl.AssignMenus)
 
 				// 菜单管理
 				admin.GET("/menus", menuCtrl.List)
 				admin.GET("/menus/all", menuCtrl.ListAll)
-				admin.POST("/menus", menuCtrl.Create)
-				admin.PUT("/menus/:id", menuCtrl.Update)
-				admin.DELETE("/menus/:id", menuCtrl.Delete)
 
 				// 任务管理
 				admin.GET("/tasks", taskCtrl.List)
@@ -81,6 +107,85 @@ func SetupRoutes(r *gin.Engine) {
 				admin.POST("/rewards", rewardCtrl.Create)
 				admin.PUT("/rewards/:id", rewardCtrl.Update)
 				admin.DELETE("/rewards/:id", rewardCtrl.Delete)
+				admin.POST("/logs/:id/refund", rewardCtrl.Refund)
+				admin.GET("/rewards/:id/drops", chestCtrl.DropTable)
+				admin.PUT("/rewards/:id/drops", chestCtrl.SaveDropTable)
+				admin.GET("/chests/opens", chestCtrl.OpenList)
+
+				// 促销与优惠券
+				admin.GET("/sales", promotionCtrl.SaleList)
+				admin.POST("/sales", promotionCtrl.CreateSale)
+				admin.PUT("/sales/:id", promotionCtrl.UpdateSale)
+				admin.DELETE("/sales/:id", promotionCtrl.DeleteSale)
+				admin.GET("/coupons", promotionCtrl.CouponList)
+				admin.POST("/coupons", promotionCtrl.CreateCoupon)
+				admin.PUT("/coupons/:id", promotionCtrl.UpdateCoupon)
+				admin.DELETE("/coupons/:id", promotionCtrl.DeleteCoupon)
+				admin.GET("/coupons/:id/redemptions", promotionCtrl.CouponRedemptions)
+
+				// 每日收益上限
+				admin.GET("/earning-caps", economyCtrl.CapList)
+
+				// 签到日历
+				admin.GET("/checkin/rewards", checkinCtrl.Calendar)
+
+				// 赛季通行证
+				admin.GET("/seasons", seasonCtrl.List)
+				admin.GET("/seasons/:id/tiers", seasonCtrl.Tiers)
+				admin.GET("/seasons/:id/progress", seasonCtrl.Progress)
+
+				// 公会管理
+				admin.GET("/guilds", guildCtrl.AdminList)
+				admin.DELETE("/guilds/:id", guildCtrl.AdminDelete)
+
+				// 首领讨伐
+				admin.GET("/bosses", bossCtrl.List)
+				admin.POST("/bosses", bossCtrl.Create)
+				admin.PUT("/bosses/:id", bossCtrl.Update)
+				admin.DELETE("/bosses/:id", bossCtrl.Delete)
+				admin.GET("/bosses/:id/damages", bossCtrl.Damages)
+
+				// 排行榜
+				admin.POST("/leaderboards/refresh", leaderboardCtrl.Refresh)
+
+				// 好友挑战
+				admin.GET("/challenges", challengeCtrl.AdminList)
+
+				// 动态管理
+				admin.GET("/feed", feedCtrl.AdminList)
+				admin.DELETE("/feed/:id", feedCtrl.AdminDelete)
+				admin.DELETE("/feed/comments/:id", feedCtrl.AdminDeleteComment)
+
+				// 站内消息
+				admin.POST("/notifications", notificationCtrl.Send)
+
+				// 邮件投递
+				admin.GET("/emails", mailCtrl.List)
+				admin.POST("/emails/test", mailCtrl.Test)
+				admin.POST("/emails/:id/retry", mailCtrl.Retry)
+
+				// 空间级Webhook
+				admin.GET("/webhooks", adminWebhookCtrl.List)
+				admin.GET("/webhooks/events", adminWebhookCtrl.Events)
+				admin.POST("/webhooks", adminWebhookCtrl.Create)
+				admin.PUT("/webhooks/:id", adminWebhookCtrl.Update)
+				admin.DELETE("/webhooks/:id", adminWebhookCtrl.Delete)
+				admin.GET("/webhooks/:id/deliveries", adminWebhookCtrl.Deliveries)
+				admin.POST("/webhooks/:id/test", adminWebhookCtrl.Test)
+
+				// 币种管理
+				admin.GET("/currencies", currencyCtrl.List)
+
+				// 银行管理
+				admin.GET("/bank/products", bankCtrl.ProductList)
+				admin.GET("/bank/deposits", bankCtrl.DepositList)
+
+				// 转账记录
+				admin.GET("/transfers", transferCtrl.List)
+
+				// 账务对账
+				admin.GET("/ledger/reconcile", ledgerCtrl.Reconcile)
+				admin.POST("/ledger/reconcile", ledgerCtrl.Fix)
 
 				// 公告管理
 				admin.GET("/announcements", announcementCtrl.List)
@@ -92,12 +197,172 @@ func SetupRoutes(r *gin.Engine) {
 				admin.PUT("/theme", dashboardCtrl.UpdateThemeConfig)
 			}
 
+			// ===== 平台管理接口 (默认空间的管理员) =====
+			platform := authenticated.Group("")
+			platform.Use(middleware.PlatformAdminRequired())
+			{
+				// 空间管理
+				platform.GET("/tenants", tenantCtrl.List)
+				platform.POST("/tenants", tenantCtrl.Create)
+				platform.PUT("/tenants/:id", tenantCtrl.Update)
+
+				// 角色与菜单为全部空间共用
+				platform.POST("/roles", roleCtrl.Create)
+				platform.PUT("/roles/:id", roleCtrl.Update)
+				platform.DELETE("/roles/:id", roleCtrl.Delete)
+				platform.POST("/roles/:id/menus", roleCtrl.AssignMenus)
+				platform.POST("/menus", menuCtrl.Create)
+				platform.PUT("/menus/:id", menuCtrl.Update)
+				platform.DELETE("/menus/:id", menuCtrl.Delete)
+
+				// 币种、收益上限、签到日历、赛季和银行产品为全部空间共用的经济配置
+				platform.POST("/currencies", currencyCtrl.Create)
+				platform.PUT("/currencies/:id", currencyCtrl.Update)
+				platform.POST("/earning-caps", economyCtrl.CreateCap)
+				platform.PUT("/earning-caps/:id", economyCtrl.UpdateCap)
+				platform.DELETE("/earning-caps/:id", economyCtrl.DeleteCap)
+				platform.PUT("/checkin/rewards", checkinCtrl.SaveCalendar)
+				platform.POST("/seasons", seasonCtrl.Create)
+				platform.PUT("/seasons/:id", seasonCtrl.Update)
+				platform.DELETE("/seasons/:id", seasonCtrl.Delete)
+				platform.PUT("/seasons/:id/tiers", seasonCtrl.SaveTiers)
+				platform.POST("/bank/products", bankCtrl.CreateProduct)
+				platform.PUT("/bank/products/:id", bankCtrl.UpdateProduct)
+				platform.DELETE("/bank/products/:id", bankCtrl.DeleteProduct)
+			}
+
+			// ===== 家庭管理接口 (监护人) =====
+			household := authenticated.Group("/household")
+			household.Use(middleware.GuardianRequired())
+			{
+				household.GET("", householdCtrl.Get)
+				household.POST("", householdCtrl.Create)
+				household.PUT("", householdCtrl.Update)
+
+				// 家庭成员
+				household.POST("/children", householdCtrl.CreateChild)
+				household.DELETE("/members/:userId", householdCtrl.RemoveMember)
+				household.GET("/members/:userId/logs", householdCtrl.MemberLogs)
+
+				// 家庭任务
+				household.GET("/tasks", householdCtrl.TaskList)
+				household.POST("/tasks", householdCtrl.CreateTask)
+				household.PUT("/tasks/:id", householdCtrl.UpdateTask)
+				household.DELETE("/tasks/:id", householdCtrl.DeleteTask)
+
+				// 家庭奖励
+				household.GET("/rewards", householdCtrl.RewardList)
+				household.POST("/rewards", householdCtrl.CreateReward)
+				household.PUT("/rewards/:id", householdCtrl.UpdateReward)
+				household.DELETE("/rewards/:id", householdCtrl.DeleteReward)
+
+				// 完成审核
+				household.GET("/approvals", householdCtrl.Approvals)
+				household.POST("/approvals/:id/approve", householdCtrl.Approve)
+				household.POST("/approvals/:id/reject", householdCtrl.Reject)
+			}
+
 			// ===== 用户端接口 (普通用户) =====
 			app := authenticated.Group("/app")
 			{
 				// 用户资料
 				app.GET("/profile", dashboardCtrl.UserProfile)
 				app.GET("/logs", dashboardCtrl.UserLogs)
+				app.GET("/wallet", currencyCtrl.UserWallet)
+				app.PUT("/email", mailCtrl.UpdateSettings)
+
+				// 签到
+				app.GET("/checkin", checkinCtrl.History)
+				app.POST("/checkin", checkinCtrl.Checkin)
+				app.POST("/checkin/makeup", checkinCtrl.Makeup)
+
+				// 好友与隐私
+				app.GET("/friends", friendCtrl.List)
+				app.DELETE("/friends/:id", friendCtrl.Remove)
+				app.GET("/friends/requests", friendCtrl.Requests)
+				app.POST("/friends/requests", friendCtrl.SendRequest)
+				app.POST("/friends/requests/:id/accept", friendCtrl.Accept)
+				app.POST("/friends/requests/:id/reject", friendCtrl.Reject)
+				app.GET("/friends/blocks", friendCtrl.Blocks)
+				app.POST("/friends/blocks", friendCtrl.Block)
+				app.DELETE("/friends/blocks/:id", friendCtrl.Unblock)
+				app.GET("/users/:id/profile", friendCtrl.Profile)
+				app.GET("/privacy", friendCtrl.Privacy)
+				app.PUT("/privacy", friendCtrl.UpdatePrivacy)
+
+				// 公会
+				app.GET("/guilds", guildCtrl.List)
+				app.POST("/guilds", guildCtrl.Create)
+				app.POST("/guilds/:id/join", guildCtrl.Join)
+				app.GET("/guild", guildCtrl.Mine)
+				app.PUT("/guild", guildCtrl.Update)
+				app.POST("/guild/leave", guildCtrl.Leave)
+				app.PUT("/guild/members/:id/role", guildCtrl.SetRole)
+				app.DELETE("/guild/members/:id", guildCtrl.Kick)
+				app.GET("/guild/activities", guildCtrl.Activities)
+				app.GET("/guild/weeks", guildCtrl.Weeks)
+
+				// 监督伙伴
+				app.GET("/accountability", accountabilityCtrl.Overview)
+				app.PUT("/accountability", accountabilityCtrl.Nominate)
+				app.DELETE("/accountability", accountabilityCtrl.Remove)
+				app.POST("/accountability/:id/accept", accountabilityCtrl.Accept)
+				app.POST("/accountability/:id/decline", accountabilityCtrl.Decline)
+				app.POST("/accountability/:id/nudge", accountabilityCtrl.Nudge)
+
+				// 通知
+				app.GET("/notifications", notificationCtrl.List)
+				app.GET("/notifications/unread-count", notificationCtrl.UnreadCount)
+				app.POST("/notifications/read-all", notificationCtrl.ReadAll)
+				app.POST("/notifications/:id/read", notificationCtrl.Read)
+
+				// 任务提醒
+				app.GET("/reminders", reminderCtrl.List)
+				app.GET("/reminders/channels", reminderCtrl.Channels)
+				app.POST("/reminders", reminderCtrl.Create)
+				app.PUT("/reminders/:id", reminderCtrl.Update)
+				app.DELETE("/reminders/:id", reminderCtrl.Delete)
+
+				// Webhook
+				app.GET("/webhooks", webhookCtrl.List)
+				app.GET("/webhooks/events", webhookCtrl.Events)
+				app.POST("/webhooks", webhookCtrl.Create)
+				app.PUT("/webhooks/:id", webhookCtrl.Update)
+				app.DELETE("/webhooks/:id", webhookCtrl.Delete)
+				app.GET("/webhooks/:id/deliveries", webhookCtrl.Deliveries)
+				app.POST("/webhooks/:id/test", webhookCtrl.Test)
+
+				// 好友动态
+				app.GET("/feed", feedCtrl.List)
+				app.POST("/feed/:id/cheer", feedCtrl.Cheer)
+				app.DELETE("/feed/:id/cheer", feedCtrl.Uncheer)
+				app.GET("/feed/:id/cheers", feedCtrl.Cheers)
+				app.GET("/feed/:id/comments", feedCtrl.Comments)
+				app.POST("/feed/:id/comments", feedCtrl.Comment)
+				app.DELETE("/feed/comments/:id", feedCtrl.DeleteComment)
+
+				// 好友挑战
+				app.GET("/challenges", challengeCtrl.List)
+				app.POST("/challenges", challengeCtrl.Create)
+				app.GET("/challenges/:id", challengeCtrl.Detail)
+				app.GET("/challenges/:id/result", challengeCtrl.Result)
+				app.POST("/challenges/:id/accept", challengeCtrl.Accept)
+				app.POST("/challenges/:id/decline", challengeCtrl.Decline)
+				app.POST("/challenges/:id/cancel", challengeCtrl.Cancel)
+
+				// 排行榜
+				app.GET("/leaderboards", leaderboardCtrl.Board)
+
+				// 首领讨伐
+				app.GET("/bosses", bossCtrl.UserList)
+				app.GET("/bosses/:id", bossCtrl.Detail)
+				app.POST("/bosses/:id/join", bossCtrl.Join)
+
+				// 赛季通行证
+				app.GET("/season", seasonCtrl.Current)
+				app.POST("/season/premium", seasonCtrl.UnlockPremium)
+				app.POST("/season/tiers/:id/claim", seasonCtrl.Claim)
+				app.GET("/season/history", seasonCtrl.History)
 
 				// 任务
 				app.GET("/tasks", taskCtrl.UserTaskList)
@@ -105,7 +370,35 @@ func SetupRoutes(r *gin.Engine) {
 
 				// 奖励
 				app.GET("/rewards", rewardCtrl.UserRewardList)
+				app.GET("/rewards/:id/quote", rewardCtrl.Quote)
 				app.POST("/rewards/:id/purchase", rewardCtrl.Purchase)
+				app.POST("/rewards/:id/gift", transferCtrl.Gift)
+
+				// 宝箱与背包
+				app.GET("/rewards/:id/drops", chestCtrl.DropRates)
+				app.GET("/chests/opens", chestCtrl.UserOpenList)
+				app.GET("/chests/opens/:id/verify", chestCtrl.Verify)
+				app.GET("/items", chestCtrl.UserItems)
+
+				// 转账
+				app.GET("/transfers", transferCtrl.UserTransferList)
+				app.GET("/transfers/usage", transferCtrl.Usage)
+				app.POST("/transfers", transferCtrl.Send)
+
+				// 心愿储蓄
+				app.GET("/goals", goalCtrl.List)
+				app.POST("/goals", goalCtrl.Create)
+				app.PUT("/goals/:id", goalCtrl.Update)
+				app.POST("/goals/:id/deposit", goalCtrl.Deposit)
+				app.POST("/goals/:id/withdraw", goalCtrl.Withdraw)
+				app.POST("/goals/:id/purchase", goalCtrl.Purchase)
+				app.DELETE("/goals/:id", goalCtrl.Cancel)
+
+				// 银行
+				app.GET("/bank/products", bankCtrl.UserProductList)
+				app.GET("/bank/deposits", bankCtrl.UserDepositList)
+				app.POST("/bank/deposits", bankCtrl.OpenDeposit)
+				app.POST("/bank/deposits/:id/withdraw", bankCtrl.WithdrawDeposit)
 
 				// 公告
 				app.GET("/announcements", announcementCtrl.UserAnnouncementList)
@@ -0,0 +1,67 @@
+// Package scheduler 后台定时任务调度
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job 定时任务
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+var (
+	mu      sync.Mutex
+	jobs    []Job
+	started bool
+)
+
+// Register 注册定时任务，需在 Start 之前调用
+func Register(job Job) {
+	mu.Lock()
+	defer mu.Unlock()
+	jobs = append(jobs, job)
+}
+
+// Start 启动所有已注册的定时任务，每个任务在独立的 goroutine 中按间隔执行
+func Start() {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return
+	}
+	started = true
+
+	for _, job := range jobs {
+		go loop(job)
+	}
+	log.Printf("定时任务已启动, 共 %d 个", len(jobs))
+}
+
+// loop 按间隔循环执行任务
+func loop(job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	runOnce(job)
+	for range ticker.C {
+		runOnce(job)
+	}
+}
+
+// runOnce 执行一次任务，捕获 panic 避免影响其他任务
+func runOnce(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("定时任务 %s 异常: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(); err != nil {
+		log.Printf("定时任务 %s 执行失败: %v", job.Name, err)
+	}
+}
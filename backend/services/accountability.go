@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// RefTypeAccountability 监督伙伴通知的关联类型
+const RefTypeAccountability = "accountability"
+
+// NudgeDailyLimit 每位监督伙伴每天最多提醒次数
+const NudgeDailyLimit = 3
+
+// 监督伙伴错误
+var (
+	ErrPartnerSelf     = errors.New("不能选择自己作为监督伙伴")
+	ErrPartnerFriend   = errors.New("只能选择好友作为监督伙伴")
+	ErrPartnerTasks    = errors.New("请选择1-10个每日任务")
+	ErrPartnerNotFound = errors.New("监督关系不存在")
+	ErrNudgeLimit      = errors.New("今天的提醒次数已用完")
+)
+
+// NominatePartner 指定监督伙伴及被监督的每日任务, 替换已有的监督关系
+func NominatePartner(tx *gorm.DB, userID, partnerID uint, taskIDs []uint) (*models.Accountability, error) {
+	if userID == partnerID {
+		return nil, ErrPartnerSelf
+	}
+	if !AreFriends(tx, userID, partnerID) {
+		return nil, ErrPartnerFriend
+	}
+	taskIDs = uniqueIDs(taskIDs, 0)
+	if len(taskIDs) == 0 || len(taskIDs) > 10 {
+		return nil, ErrPartnerTasks
+	}
+	var count int64
+	tx.Model(&models.Task{}).Where("id IN ? AND type = ? AND is_active = ?", taskIDs, "daily", true).Count(&count)
+	if int(count) != len(taskIDs) {
+		return nil, ErrPartnerTasks
+	}
+
+	if err := RemovePartner(tx, userID); err != nil && !errors.Is(err, ErrPartnerNotFound) {
+		return nil, err
+	}
+	link := &models.Accountability{UserID: userID, PartnerID: partnerID, Status: FriendPending}
+	if err := tx.Create(link).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range taskIDs {
+		link.Tasks = append(link.Tasks, models.AccountabilityTask{AccountabilityID: link.ID, TaskID: id})
+	}
+	if err := tx.Create(&link.Tasks).Error; err != nil {
+		return nil, err
+	}
+
+	var user models.SysUser
+	tx.First(&user, userID)
+	return link, Notify(tx, &models.Notification{
+		UserID:   partnerID,
+		Type:     NotifyPartnerInvite,
+		Title:    "监督伙伴邀请",
+		Body:     fmt.Sprintf("%s 希望你成为TA的监督伙伴", displayName(&user)),
+		Link:     "/accountability",
+		SenderID: userID,
+		RefType:  RefTypeAccountability,
+		RefID:    link.ID,
+	})
+}
+
+// RespondPartner 监督伙伴接受邀请, 或拒绝邀请/退出监督
+func RespondPartner(tx *gorm.DB, partnerID, linkID uint, accept bool) (*models.Accountability, error) {
+	var link models.Accountability
+	if err := tx.Where("id = ? AND partner_id = ?", linkID, partnerID).First(&link).Error; err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	if !accept {
+		return &link, deleteAccountability(tx, &link)
+	}
+	if link.Status != FriendPending {
+		return nil, ErrPartnerNotFound
+	}
+
+	// 从接受当天开始监督, 之前的日期不做检查
+	now := time.Now()
+	if err := tx.Model(&link).Updates(map[string]interface{}{
+		"status":            FriendAccepted,
+		"accepted_at":       now,
+		"last_checked_date": now.AddDate(0, 0, -1).Format("2006-01-02"),
+	}).Error; err != nil {
+		return nil, err
+	}
+	link.Status, link.AcceptedAt = FriendAccepted, &now
+	return &link, nil
+}
+
+// RemovePartner 解除自己的监督关系
+func RemovePartner(tx *gorm.DB, userID uint) error {
+	var link models.Accountability
+	if err := tx.Where("user_id = ?", userID).First(&link).Error; err != nil {
+		return ErrPartnerNotFound
+	}
+	return deleteAccountability(tx, &link)
+}
+
+// deleteAccountability 删除监督关系及被监督任务
+func deleteAccountability(tx *gorm.DB, link *models.Accountability) error {
+	if err := tx.Where("accountability_id = ?", link.ID).Delete(&models.AccountabilityTask{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(link).Error
+}
+
+// Nudge 监督伙伴向被监督人发送提醒
+func Nudge(tx *gorm.DB, partnerID, linkID uint, message string) error {
+	var link models.Accountability
+	if err := tx.Where("id = ? AND partner_id = ? AND status = ?", linkID, partnerID, FriendAccepted).
+		First(&link).Error; err != nil {
+		return ErrPartnerNotFound
+	}
+
+	var sent int64
+	tx.Model(&models.Notification{}).
+		Where("user_id = ? AND sender_id = ? AND type = ? AND created_at >= ?",
+			link.UserID, partnerID, NotifyNudge, time.Now().Format("2006-01-02")).
+		Count(&sent)
+	if sent >= NudgeDailyLimit {
+		return ErrNudgeLimit
+	}
+
+	var partner models.SysUser
+	tx.First(&partner, partnerID)
+	body := strings.TrimSpace(message)
+	if body == "" {
+		body = "别忘了今天的任务哦!"
+	}
+	if len([]rune(body)) > 200 {
+		body = string([]rune(body)[:200])
+	}
+	return Notify(tx, &models.Notification{
+		UserID:   link.UserID,
+		Type:     NotifyNudge,
+		Title:    displayName(&partner) + " 提醒你",
+		Body:     body,
+		Link:     "/tasks",
+		SenderID: partnerID,
+		RefType:  RefTypeAccountability,
+		RefID:    link.ID,
+	})
+}
+
+// WatchedTasksToday 被监督任务今日完成情况
+func WatchedTasksToday(db *gorm.DB, link *models.Accountability) map[uint]bool {
+	taskIDs := make([]uint, 0, len(link.Tasks))
+	for _, t := range link.Tasks {
+		taskIDs = append(taskIDs, t.TaskID)
+	}
+	done := make(map[uint]bool, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return done
+	}
+	var completed []uint
+	db.Model(&models.UserTask{}).
+		Where("user_id = ? AND task_id IN ? AND completed_at >= ? AND status = ?",
+			link.UserID, taskIDs, time.Now().Format("2006-01-02"), TaskApproved).
+		Pluck("task_id", &completed)
+	for _, id := range completed {
+		done[id] = true
+	}
+	return done
+}
+
+// EvaluateRollover 日终检查: 被监督人昨天漏做被监督的每日任务时通知其监督伙伴
+func EvaluateRollover(db *gorm.DB) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	day := yesterday.Format("2006-01-02")
+
+	var links []models.Accountability
+	if err := db.Preload("Tasks.Task").
+		Where("status = ? AND last_checked_date < ?", FriendAccepted, day).
+		Find(&links).Error; err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// 只检查用户当前仍可见的任务, 退出家庭后家庭任务不再计入
+			taskIDs := make([]uint, 0, len(link.Tasks))
+			for _, t := range link.Tasks {
+				taskIDs = append(taskIDs, t.TaskID)
+			}
+			var visibleIDs []uint
+			if len(taskIDs) > 0 {
+				visibleTasks(tx, link.UserID).Where("id IN ?", taskIDs).Pluck("id", &visibleIDs)
+			}
+			visible := make(map[uint]bool, len(visibleIDs))
+			for _, id := range visibleIDs {
+				visible[id] = true
+			}
+
+			var missed []string
+			for _, t := range link.Tasks {
+				if t.Task == nil || !visible[t.TaskID] || !t.Task.IsActive || !t.Task.CreatedAt.Before(today) {
+					continue
+				}
+				if completedOn(tx, link.UserID, []uint{t.TaskID}, yesterday) == 0 {
+					missed = append(missed, t.Task.Title)
+				}
+			}
+			if len(missed) > 0 {
+				var user models.SysUser
+				tx.First(&user, link.UserID)
+				if err := Notify(tx, &models.Notification{
+					UserID:  link.PartnerID,
+					Type:    NotifyPartnerMissed,
+					Title:   fmt.Sprintf("%s 昨天漏做了%d个任务", displayName(&user), len(missed)),
+					Body:    fmt.Sprintf("%s 未完成: %s", day, strings.Join(missed, "、")),
+					Link:    "/accountability",
+					RefType: RefTypeAccountability,
+					RefID:   link.ID,
+				}); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&link).Update("last_checked_date", day).Error
+		})
+		if err != nil {
+			return fmt.Errorf("监督关系 %d 日终检查失败: %w", link.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 银行流水关联类型
+const (
+	RefTypeBank         = "bank"          // 本金存入/返还
+	RefTypeBankInterest = "bank_interest" // 到期利息
+	RefTypeBankPenalty  = "bank_penalty"  // 提前支取罚金
+)
+
+// 存款状态
+const (
+	DepositActive    = "active"
+	DepositMatured   = "matured"
+	DepositWithdrawn = "withdrawn"
+)
+
+// 银行错误
+var (
+	ErrDepositTooSmall = errors.New("低于最低存入金额")
+	ErrDepositOverMax  = errors.New("超过该产品存款上限")
+	ErrDepositClosed   = errors.New("存款已结清")
+)
+
+// OpenDeposit 存入定期存款
+func OpenDeposit(tx *gorm.DB, userID uint, product *models.BankProduct, amount int) (*models.BankDeposit, error) {
+	if amount < product.MinDeposit || amount <= 0 {
+		return nil, ErrDepositTooSmall
+	}
+
+	// 先锁定用户，保证同一用户的上限校验串行
+	if _, err := LockUser(tx, userID); err != nil {
+		return nil, err
+	}
+	if product.MaxDeposit > 0 {
+		var active int
+		tx.Model(&models.BankDeposit{}).
+			Where("user_id = ? AND product_id = ? AND status = ?", userID, product.ID, DepositActive).
+			Select("COALESCE(SUM(principal), 0)").
+			Scan(&active)
+		if active+amount > product.MaxDeposit {
+			return nil, ErrDepositOverMax
+		}
+	}
+
+	now := time.Now()
+	deposit := &models.BankDeposit{
+		UserID:      userID,
+		ProductID:   product.ID,
+		Principal:   amount,
+		Rate:        product.Rate,
+		PenaltyRate: product.PenaltyRate,
+		Interest:    int(float64(amount) * product.Rate / 100),
+		Status:      DepositActive,
+		StartAt:     now,
+		MaturityAt:  now.AddDate(0, 0, product.TermDays),
+	}
+	if err := tx.Create(deposit).Error; err != nil {
+		return nil, err
+	}
+
+	if _, err := ChangeGold(tx, userID, -amount, LogMeta{
+		Description: fmt.Sprintf("定期存款: %s", product.Name),
+		RefType:     RefTypeBank,
+		RefID:       deposit.ID,
+	}); err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// lockDeposit 以行锁读取进行中的存款
+func lockDeposit(tx *gorm.DB, depositID uint) (*models.BankDeposit, error) {
+	var deposit models.BankDeposit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deposit, depositID).Error; err != nil {
+		return nil, err
+	}
+	if deposit.Status != DepositActive {
+		return &deposit, ErrDepositClosed
+	}
+	return &deposit, nil
+}
+
+// WithdrawDeposit 支取存款
+// 未到期时按存入时的罚金比例扣除本金且不计利息，已到期(定时任务尚未结算)则正常结算
+func WithdrawDeposit(tx *gorm.DB, userID, depositID uint) (*models.BankDeposit, error) {
+	deposit, err := lockDeposit(tx, depositID)
+	if err != nil {
+		return deposit, err
+	}
+	if deposit.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if !time.Now().Before(deposit.MaturityAt) {
+		return deposit, settle(tx, deposit)
+	}
+
+	meta := LogMeta{Description: "定期存款提前支取, 本金返还", RefType: RefTypeBank, RefID: deposit.ID}
+	if _, err := ChangeGold(tx, userID, deposit.Principal, meta); err != nil {
+		return deposit, err
+	}
+	penalty := int(float64(deposit.Principal) * deposit.PenaltyRate / 100)
+	if penalty > 0 {
+		meta = LogMeta{
+			Description: fmt.Sprintf("定期存款提前支取罚金 (%.2f%%)", deposit.PenaltyRate),
+			RefType:     RefTypeBankPenalty,
+			RefID:       deposit.ID,
+		}
+		if _, err := ChangeGold(tx, userID, -penalty, meta); err != nil {
+			return deposit, err
+		}
+	}
+
+	now := time.Now()
+	deposit.Status, deposit.Penalty, deposit.SettledAt = DepositWithdrawn, penalty, &now
+	return deposit, tx.Model(deposit).Updates(map[string]interface{}{
+		"status":     DepositWithdrawn,
+		"penalty":    penalty,
+		"settled_at": now,
+	}).Error
+}
+
+// settle 到期结算：返还本金并发放利息
+func settle(tx *gorm.DB, deposit *models.BankDeposit) error {
+	meta := LogMeta{Description: "定期存款到期, 本金返还", RefType: RefTypeBank, RefID: deposit.ID}
+	if _, err := ChangeGold(tx, deposit.UserID, deposit.Principal, meta); err != nil {
+		return err
+	}
+	meta = LogMeta{
+		Description: fmt.Sprintf("定期存款利息 (%.2f%%)", deposit.Rate),
+		RefType:     RefTypeBankInterest,
+		RefID:       deposit.ID,
+	}
+	if _, err := ChangeGold(tx, deposit.UserID, deposit.Interest, meta); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deposit.Status, deposit.SettledAt = DepositMatured, &now
+	return tx.Model(deposit).Updates(map[string]interface{}{
+		"status":     DepositMatured,
+		"settled_at": now,
+	}).Error
+}
+
+// SettleMaturedDeposits 结算所有已到期的存款，由定时任务调用
+func SettleMaturedDeposits(db *gorm.DB) error {
+	var ids []uint
+	if err := db.Model(&models.BankDeposit{}).
+		Where("status = ? AND maturity_at <= ?", DepositActive, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			deposit, err := lockDeposit(tx, id)
+			if err != nil {
+				return err
+			}
+			return settle(tx, deposit)
+		})
+		if err != nil && !errors.Is(err, ErrDepositClosed) {
+			return fmt.Errorf("结算存款 %d 失败: %w", id, err)
+		}
+	}
+	return nil
+}
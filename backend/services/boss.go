@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefTypeBoss 首领活动流水的关联类型
+const RefTypeBoss = "boss"
+
+// 首领状态
+const (
+	BossActive   = "active"
+	BossDefeated = "defeated"
+	BossExpired  = "expired"
+)
+
+// 战斗记录类型
+const (
+	BossAttack  = "attack"
+	BossCounter = "counter"
+)
+
+// 首领错误
+var (
+	ErrBossNotActive = errors.New("首领活动未开始或已结束")
+	ErrBossJoined    = errors.New("已参与该首领讨伐")
+)
+
+// activeBossQuery 进行中的首领
+func activeBossQuery(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("status = ? AND start_at <= ? AND end_at > ?", BossActive, now, now)
+}
+
+// JoinBoss 参与首领讨伐
+func JoinBoss(tx *gorm.DB, userID, bossID uint) (*models.BossParticipant, error) {
+	var boss models.Boss
+	if err := activeBossQuery(tx, time.Now()).First(&boss, bossID).Error; err != nil {
+		return nil, ErrBossNotActive
+	}
+	participant := &models.BossParticipant{BossID: boss.ID, UserID: userID, JoinedAt: time.Now()}
+	if err := tx.Create(participant).Error; err != nil {
+		return nil, ErrBossJoined
+	}
+	return participant, nil
+}
+
+// AttackBosses 参与者完成任务时对其参与的全部进行中首领造成伤害, 返回造成的总伤害
+func AttackBosses(tx *gorm.DB, userID uint, task *models.Task, exp int) (int, error) {
+	var bossIDs []uint
+	activeBossQuery(tx.Model(&models.Boss{}), time.Now()).
+		Joins("JOIN boss_participant ON boss_participant.boss_id = boss.id").
+		Where("boss_participant.user_id = ?", userID).
+		Order("boss.id").
+		Pluck("boss.id", &bossIDs)
+
+	total := 0
+	for _, id := range bossIDs {
+		var boss models.Boss
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&boss, id).Error; err != nil {
+			return total, err
+		}
+		damage := exp * boss.DamagePercent / 100
+		if boss.Status != BossActive || damage <= 0 {
+			continue
+		}
+		if damage > boss.HP {
+			damage = boss.HP
+		}
+
+		if err := tx.Create(&models.BossDamage{
+			BossID:      boss.ID,
+			UserID:      userID,
+			Kind:        BossAttack,
+			Damage:      damage,
+			TaskID:      task.ID,
+			Description: "完成任务: " + task.Title,
+		}).Error; err != nil {
+			return total, err
+		}
+		if err := tx.Model(&models.BossParticipant{}).
+			Where("boss_id = ? AND user_id = ?", boss.ID, userID).
+			Update("damage", gorm.Expr("damage + ?", damage)).Error; err != nil {
+			return total, err
+		}
+		total += damage
+
+		boss.HP -= damage
+		if err := tx.Model(&boss).Update("hp", boss.HP).Error; err != nil {
+			return total, err
+		}
+		if boss.HP == 0 {
+			if err := defeatBoss(tx, &boss); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// defeatBoss 首领被击败, 向全体参与者发放战利品
+func defeatBoss(tx *gorm.DB, boss *models.Boss) error {
+	now := time.Now()
+	if err := tx.Model(boss).Updates(map[string]interface{}{
+		"status":      BossDefeated,
+		"defeated_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	var chest *models.Reward
+	if boss.LootChestID > 0 {
+		var reward models.Reward
+		if err := tx.First(&reward, boss.LootChestID).Error; err == nil && reward.Type == RewardTypeChest {
+			chest = &reward
+		}
+	}
+
+	var participants []models.BossParticipant
+	if err := tx.Where("boss_id = ? AND rewarded = ?", boss.ID, false).Order("user_id").
+		Find(&participants).Error; err != nil {
+		return err
+	}
+	meta := LogMeta{
+		Description: fmt.Sprintf("击败首领: %s", boss.Name),
+		RefType:     RefTypeBoss,
+		RefID:       boss.ID,
+	}
+	for _, p := range participants {
+		if err := GrantBundle(tx, p.UserID, &boss.RewardBundle, meta); err != nil {
+			return err
+		}
+		if chest != nil {
+			if _, err := OpenChest(tx, p.UserID, chest); err != nil && !errors.Is(err, ErrChestEmpty) {
+				return err
+			}
+		}
+		if err := PublishFeed(tx, p.UserID, FeedAchievement, "参与击败了首领「"+boss.Name+"」", RefTypeBoss, boss.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&p).Update("rewarded", true).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BossCounterAttack 结算首领反击: 参与者昨日每漏做一个每日任务, 首领回复生命并扣除参与者金币
+// 每个首领每天只结算一次, 同时将超时未被击败的首领标记为逃走
+func BossCounterAttack(db *gorm.DB) error {
+	now := time.Now()
+	if err := db.Model(&models.Boss{}).
+		Where("status = ? AND end_at <= ?", BossActive, now).
+		Update("status", BossExpired).Error; err != nil {
+		return err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	day := yesterday.Format("2006-01-02")
+
+	var bosses []models.Boss
+	activeBossQuery(db, now).
+		Where("start_at < ? AND (last_counter_date IS NULL OR last_counter_date < ?)", today, day).
+		Find(&bosses)
+
+	for _, b := range bosses {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var boss models.Boss
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&boss, b.ID).Error; err != nil {
+				return err
+			}
+			if boss.Status != BossActive || boss.LastCounterDate >= day {
+				return nil
+			}
+
+			var participants []models.BossParticipant
+			tx.Where("boss_id = ? AND joined_at < ?", boss.ID, today).Find(&participants)
+			for _, p := range participants {
+				// 参与者可见且昨日之前已上架的每日任务
+				var dailyIDs []uint
+				visibleTasks(tx, p.UserID).
+					Where("type = ? AND is_active = ? AND created_at < ?", "daily", true, yesterday).
+					Pluck("id", &dailyIDs)
+				missed := len(dailyIDs) - completedOn(tx, p.UserID, dailyIDs, yesterday)
+				if missed <= 0 {
+					continue
+				}
+				if err := counterAttack(tx, &boss, &p, missed, day); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&boss).Updates(map[string]interface{}{
+				"hp":                boss.HP,
+				"last_counter_date": day,
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("首领 %d 反击结算失败: %w", b.ID, err)
+		}
+	}
+	return nil
+}
+
+// completedOn 用户在指定日期完成了其中多少个任务
+func completedOn(db *gorm.DB, userID uint, taskIDs []uint, day time.Time) int {
+	if len(taskIDs) == 0 {
+		return 0
+	}
+	var count int64
+	db.Model(&models.UserTask{}).
+		Where("user_id = ? AND task_id IN ? AND completed_at >= ? AND completed_at < ? AND status = ?",
+			userID, taskIDs, day, day.AddDate(0, 0, 1), TaskApproved).
+		Distinct("task_id").
+		Count(&count)
+	return int(count)
+}
+
+// counterAttack 对单个参与者结算反击, 扣除的金币不超过其余额
+func counterAttack(tx *gorm.DB, boss *models.Boss, p *models.BossParticipant, missed int, day string) error {
+	heal := missed * boss.CounterHeal
+	if boss.HP+heal > boss.MaxHP {
+		heal = boss.MaxHP - boss.HP
+	}
+	boss.HP += heal
+
+	goldLost := missed * boss.CounterGold
+	if goldLost > 0 {
+		user, err := LockUser(tx, p.UserID)
+		if err != nil {
+			return err
+		}
+		if goldLost > user.Gold {
+			goldLost = user.Gold
+		}
+		if _, err := ChangeGold(tx, p.UserID, -goldLost, LogMeta{
+			Description: fmt.Sprintf("首领「%s」反击: %s 漏做%d个每日任务", boss.Name, day, missed),
+			RefType:     RefTypeBoss,
+			RefID:       boss.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(p).Update("missed", gorm.Expr("missed + ?", missed)).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.BossDamage{
+		BossID:      boss.ID,
+		UserID:      p.UserID,
+		Kind:        BossCounter,
+		Damage:      heal,
+		GoldLost:    goldLost,
+		Description: fmt.Sprintf("%s 漏做%d个每日任务", day, missed),
+	}).Error
+}
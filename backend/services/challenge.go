@@ -0,0 +1,334 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefTypeChallenge 挑战押注托管与结算流水的关联类型
+const RefTypeChallenge = "challenge"
+
+// 挑战状态
+const (
+	ChallengeActive    = "active"
+	ChallengeSettled   = "settled"
+	ChallengeCancelled = "cancelled"
+)
+
+// 挑战参与状态
+const (
+	ParticipantInvited  = "invited"
+	ParticipantAccepted = "accepted"
+	ParticipantDeclined = "declined"
+	ParticipantExpired  = "expired"
+)
+
+// 挑战错误
+var (
+	ErrChallengeParam    = errors.New("挑战次数需大于0, 天数需在1-30之间, 押注不能为负")
+	ErrChallengeInvitees = errors.New("请邀请1-10位好友")
+	ErrChallengeFriend   = errors.New("只能邀请好友参与挑战")
+	ErrChallengeTask     = errors.New("任务不存在或已下架")
+	ErrChallengeClosed   = errors.New("挑战已结束")
+	ErrChallengeInvite   = errors.New("没有待处理的挑战邀请")
+	ErrChallengeCancel   = errors.New("已有好友接受挑战, 无法取消")
+	ErrChallengeNotOwner = errors.New("只有发起人可以取消挑战")
+)
+
+// CreateChallenge 发起挑战并托管发起人的押注
+func CreateChallenge(tx *gorm.DB, creatorID, taskID uint, target, days, stake int, friendIDs []uint) (*models.Challenge, error) {
+	if target <= 0 || days < 1 || days > 30 || stake < 0 {
+		return nil, ErrChallengeParam
+	}
+	invitees := uniqueIDs(friendIDs, creatorID)
+	if len(invitees) == 0 || len(invitees) > 10 {
+		return nil, ErrChallengeInvitees
+	}
+	for _, id := range invitees {
+		if !AreFriends(tx, creatorID, id) {
+			return nil, ErrChallengeFriend
+		}
+	}
+	var task models.Task
+	if err := tx.First(&task, taskID).Error; err != nil || !task.IsActive {
+		return nil, ErrChallengeTask
+	}
+
+	now := time.Now()
+	challenge := &models.Challenge{
+		CreatorID: creatorID,
+		TaskID:    task.ID,
+		Target:    target,
+		Days:      days,
+		Stake:     stake,
+		Pot:       stake,
+		Status:    ChallengeActive,
+		StartAt:   now,
+		EndAt:     now.AddDate(0, 0, days),
+	}
+	if err := tx.Create(challenge).Error; err != nil {
+		return nil, err
+	}
+	if err := escrowStake(tx, creatorID, challenge, &task); err != nil {
+		return nil, err
+	}
+
+	participants := []models.ChallengeParticipant{{
+		ChallengeID: challenge.ID,
+		UserID:      creatorID,
+		Status:      ParticipantAccepted,
+		Staked:      stake,
+		RespondedAt: &now,
+	}}
+	for _, id := range invitees {
+		participants = append(participants, models.ChallengeParticipant{
+			ChallengeID: challenge.ID,
+			UserID:      id,
+			Status:      ParticipantInvited,
+		})
+	}
+	if err := tx.Create(&participants).Error; err != nil {
+		return nil, err
+	}
+	challenge.Participants = participants
+	return challenge, nil
+}
+
+// RespondChallenge 接受或拒绝挑战邀请, 接受时托管押注
+func RespondChallenge(tx *gorm.DB, userID, challengeID uint, accept bool) (*models.ChallengeParticipant, error) {
+	challenge, err := lockChallenge(tx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Status != ChallengeActive || !time.Now().Before(challenge.EndAt) {
+		return nil, ErrChallengeClosed
+	}
+
+	var participant models.ChallengeParticipant
+	if err := tx.Where("challenge_id = ? AND user_id = ? AND status = ?", challenge.ID, userID, ParticipantInvited).
+		First(&participant).Error; err != nil {
+		return nil, ErrChallengeInvite
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"responded_at": now, "status": ParticipantDeclined}
+	if accept {
+		var task models.Task
+		tx.Unscoped().First(&task, challenge.TaskID)
+		if err := escrowStake(tx, userID, challenge, &task); err != nil {
+			return nil, err
+		}
+		if err := tx.Model(challenge).Update("pot", gorm.Expr("pot + ?", challenge.Stake)).Error; err != nil {
+			return nil, err
+		}
+		updates["status"] = ParticipantAccepted
+		updates["staked"] = challenge.Stake
+	}
+	if err := tx.Model(&participant).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// CancelChallenge 发起人在无人接受前取消挑战, 退回押注
+func CancelChallenge(tx *gorm.DB, userID, challengeID uint) error {
+	challenge, err := lockChallenge(tx, challengeID)
+	if err != nil {
+		return err
+	}
+	if challenge.CreatorID != userID {
+		return ErrChallengeNotOwner
+	}
+	if challenge.Status != ChallengeActive {
+		return ErrChallengeClosed
+	}
+	var accepted int64
+	tx.Model(&models.ChallengeParticipant{}).
+		Where("challenge_id = ? AND user_id <> ? AND status = ?", challenge.ID, userID, ParticipantAccepted).
+		Count(&accepted)
+	if accepted > 0 {
+		return ErrChallengeCancel
+	}
+	return closeWithRefund(tx, challenge, "挑战取消, 退回押注")
+}
+
+// SettleChallenge 结算到期的挑战: 统计完成次数, 达标者平分奖池, 无人达标时退回押注
+func SettleChallenge(tx *gorm.DB, challengeID uint) error {
+	challenge, err := lockChallenge(tx, challengeID)
+	if err != nil {
+		return err
+	}
+	if challenge.Status != ChallengeActive || time.Now().Before(challenge.EndAt) {
+		return nil
+	}
+
+	var participants []models.ChallengeParticipant
+	if err := tx.Where("challenge_id = ? AND status = ?", challenge.ID, ParticipantAccepted).
+		Order("id").Find(&participants).Error; err != nil {
+		return err
+	}
+	// 没有好友接受挑战
+	if len(participants) < 2 {
+		return closeWithRefund(tx, challenge, "挑战无人应战, 退回押注")
+	}
+
+	for i := range participants {
+		participants[i].Progress = ChallengeProgress(tx, challenge, participants[i].UserID)
+	}
+
+	meta := LogMeta{RefType: RefTypeChallenge, RefID: challenge.ID}
+	if splitPot(participants, challenge.Target, challenge.Pot) {
+		meta.Description = fmt.Sprintf("挑战 #%d 获胜, 分得奖池", challenge.ID)
+	} else {
+		meta.Description = fmt.Sprintf("挑战 #%d 无人达标, 退回押注", challenge.ID)
+	}
+
+	for i := range participants {
+		p := &participants[i]
+		if _, err := ChangeGold(tx, p.UserID, p.Payout, meta); err != nil {
+			return err
+		}
+		if p.IsWinner {
+			title := fmt.Sprintf("赢得了挑战 #%d", challenge.ID)
+			if err := PublishFeed(tx, p.UserID, FeedAchievement, title, RefTypeChallenge, challenge.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(p).Updates(map[string]interface{}{
+			"progress":  p.Progress,
+			"is_winner": p.IsWinner,
+			"payout":    p.Payout,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return finishChallenge(tx, challenge, ChallengeSettled)
+}
+
+// splitPot 按完成次数标记获胜者并计算各参与者的发放金额, 返回是否有人达标
+// 达标者平分奖池, 除不尽的部分归完成次数最多的获胜者 (相同时取先加入者); 无人达标时押注原路退回
+func splitPot(participants []models.ChallengeParticipant, target, pot int) bool {
+	var winners []*models.ChallengeParticipant
+	best := -1
+	for i := range participants {
+		p := &participants[i]
+		if p.Progress >= target {
+			p.IsWinner = true
+			winners = append(winners, p)
+			if best < 0 || p.Progress > participants[best].Progress {
+				best = i
+			}
+		}
+	}
+
+	if len(winners) == 0 {
+		for i := range participants {
+			participants[i].Payout = participants[i].Staked
+		}
+		return false
+	}
+	share := pot / len(winners)
+	for _, w := range winners {
+		w.Payout = share
+	}
+	participants[best].Payout += pot - share*len(winners)
+	return true
+}
+
+// SettleDueChallenges 结算全部到期的挑战
+func SettleDueChallenges(db *gorm.DB) error {
+	var ids []uint
+	if err := db.Model(&models.Challenge{}).
+		Where("status = ? AND end_at <= ?", ChallengeActive, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return SettleChallenge(tx, id)
+		}); err != nil {
+			return fmt.Errorf("结算挑战 %d 失败: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// ChallengeProgress 用户在挑战期间完成指定任务的次数
+func ChallengeProgress(db *gorm.DB, challenge *models.Challenge, userID uint) int {
+	var count int64
+	db.Model(&models.UserTask{}).
+		Where("user_id = ? AND task_id = ? AND completed_at >= ? AND completed_at < ? AND status = ?",
+			userID, challenge.TaskID, challenge.StartAt, challenge.EndAt, TaskApproved).
+		Count(&count)
+	return int(count)
+}
+
+// lockChallenge 以行锁读取挑战
+func lockChallenge(tx *gorm.DB, id uint) (*models.Challenge, error) {
+	var challenge models.Challenge
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&challenge, id).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// escrowStake 托管参与者押注
+func escrowStake(tx *gorm.DB, userID uint, challenge *models.Challenge, task *models.Task) error {
+	_, err := ChangeGold(tx, userID, -challenge.Stake, LogMeta{
+		Description: fmt.Sprintf("挑战 #%d 押注: %s %d次", challenge.ID, task.Title, challenge.Target),
+		RefType:     RefTypeChallenge,
+		RefID:       challenge.ID,
+	})
+	return err
+}
+
+// closeWithRefund 退回全部已托管押注并取消挑战
+func closeWithRefund(tx *gorm.DB, challenge *models.Challenge, desc string) error {
+	var participants []models.ChallengeParticipant
+	tx.Where("challenge_id = ? AND status = ?", challenge.ID, ParticipantAccepted).Find(&participants)
+	for _, p := range participants {
+		if _, err := ChangeGold(tx, p.UserID, p.Staked, LogMeta{
+			Description: fmt.Sprintf("挑战 #%d %s", challenge.ID, desc),
+			RefType:     RefTypeChallenge,
+			RefID:       challenge.ID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Model(&p).Update("payout", p.Staked).Error; err != nil {
+			return err
+		}
+	}
+	return finishChallenge(tx, challenge, ChallengeCancelled)
+}
+
+// finishChallenge 更新挑战最终状态, 未响应的邀请标记为过期
+func finishChallenge(tx *gorm.DB, challenge *models.Challenge, status string) error {
+	if err := tx.Model(&models.ChallengeParticipant{}).
+		Where("challenge_id = ? AND status = ?", challenge.ID, ParticipantInvited).
+		Update("status", ParticipantExpired).Error; err != nil {
+		return err
+	}
+	return tx.Model(challenge).Updates(map[string]interface{}{
+		"status":     status,
+		"settled_at": time.Now(),
+	}).Error
+}
+
+// uniqueIDs 去重并排除指定用户
+func uniqueIDs(ids []uint, exclude uint) []uint {
+	seen := map[uint]bool{exclude: true}
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"life-rpg/models"
+)
+
+func TestSplitPot(t *testing.T) {
+	tests := []struct {
+		name        string
+		progress    []int
+		target, pot int
+		wantWin     bool
+		wantPayout  []int
+		wantWinners []bool
+	}{
+		{"唯一获胜者独得奖池", []int{5, 2, 1}, 5, 300, true, []int{300, 0, 0}, []bool{true, false, false}},
+		{"平分奖池", []int{5, 6, 1}, 5, 300, true, []int{150, 150, 0}, []bool{true, true, false}},
+		{"余数归完成最多者", []int{5, 7, 6}, 5, 100, true, []int{33, 34, 33}, []bool{true, true, true}},
+		{"完成次数相同时余数归先加入者", []int{6, 6, 0}, 5, 101, true, []int{51, 50, 0}, []bool{true, true, false}},
+		{"无人达标退回押注", []int{4, 3, 0}, 5, 300, false, []int{100, 100, 100}, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			participants := make([]models.ChallengeParticipant, len(tt.progress))
+			for i, p := range tt.progress {
+				participants[i] = models.ChallengeParticipant{Progress: p, Staked: 100}
+			}
+
+			if got := splitPot(participants, tt.target, tt.pot); got != tt.wantWin {
+				t.Errorf("splitPot() = %v, want %v", got, tt.wantWin)
+			}
+			payouts, winners, total := make([]int, len(participants)), make([]bool, len(participants)), 0
+			for i, p := range participants {
+				payouts[i], winners[i] = p.Payout, p.IsWinner
+				total += p.Payout
+			}
+			if !reflect.DeepEqual(payouts, tt.wantPayout) {
+				t.Errorf("payout = %v, want %v", payouts, tt.wantPayout)
+			}
+			if !reflect.DeepEqual(winners, tt.wantWinners) {
+				t.Errorf("isWinner = %v, want %v", winners, tt.wantWinners)
+			}
+			if tt.wantWin && total != tt.pot {
+				t.Errorf("发放合计 %d, 奖池 %d", total, tt.pot)
+			}
+		})
+	}
+}
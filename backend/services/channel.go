@@ -0,0 +1,79 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// Channel 站内通知之外的通知发送渠道, 如邮件、Webhook
+type Channel interface {
+	// Name 渠道标识, 用于用户选择
+	Name() string
+	// Send 将通知发送给用户
+	Send(db *gorm.DB, user *models.SysUser, n *models.Notification) error
+}
+
+var (
+	channelMu sync.RWMutex
+	channels  = map[string]Channel{}
+)
+
+// RegisterChannel 注册通知渠道, 同名渠道会被替换
+func RegisterChannel(ch Channel) {
+	channelMu.Lock()
+	defer channelMu.Unlock()
+	channels[ch.Name()] = ch
+}
+
+// ChannelNames 已注册的渠道
+func ChannelNames() []string {
+	channelMu.RLock()
+	defer channelMu.RUnlock()
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseChannels 解析并校验逗号分隔的渠道列表, 返回去重后的渠道名
+func ParseChannels(value string) ([]string, bool) {
+	channelMu.RLock()
+	defer channelMu.RUnlock()
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := channels[name]; !ok {
+			return nil, false
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, true
+}
+
+// DeliverChannels 通过指定渠道发送通知, 单个渠道失败只记录日志
+func DeliverChannels(db *gorm.DB, user *models.SysUser, n *models.Notification, names []string) {
+	for _, name := range names {
+		channelMu.RLock()
+		ch, ok := channels[name]
+		channelMu.RUnlock()
+		if !ok {
+			continue
+		}
+		if err := ch.Send(db, user, n); err != nil {
+			log.Printf("通知渠道 %s 发送失败 (用户 %d): %v", name, user.ID, err)
+		}
+	}
+}
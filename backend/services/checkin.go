@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// RefTypeCheckin 签到流水的关联类型
+const RefTypeCheckin = "checkin"
+
+// 签到错误
+var (
+	ErrCheckedIn   = errors.New("该日已签到")
+	ErrMakeupDate  = errors.New("只能补签本月今天之前的日期")
+	ErrMakeupLimit = errors.New("本月补签次数已用完")
+)
+
+// Checkin 为用户在指定日期签到，makeup 为 true 时先扣除补签费用
+func Checkin(tx *gorm.DB, userID uint, date time.Time, makeup bool) (*models.UserCheckin, error) {
+	if _, err := LockUser(tx, userID); err != nil {
+		return nil, err
+	}
+
+	day := date.Format("2006-01-02")
+	month := date.Format("2006-01")
+
+	var exists int64
+	tx.Model(&models.UserCheckin{}).Where("user_id = ? AND date = ?", userID, day).Count(&exists)
+	if exists > 0 {
+		return nil, ErrCheckedIn
+	}
+
+	var monthCount, makeupCount int64
+	tx.Model(&models.UserCheckin{}).Where("user_id = ? AND month = ?", userID, month).Count(&monthCount)
+	if makeup {
+		tx.Model(&models.UserCheckin{}).Where("user_id = ? AND month = ? AND is_makeup = ?", userID, month, true).Count(&makeupCount)
+		if int(makeupCount) >= config.AppConfig.Checkin.MakeupLimit {
+			return nil, ErrMakeupLimit
+		}
+	}
+
+	seq := int(monthCount) + 1
+	var reward models.CheckinReward
+	tx.Where("day = ?", seq).First(&reward)
+
+	checkin := &models.UserCheckin{
+		UserID:   userID,
+		Date:     day,
+		Month:    month,
+		Seq:      seq,
+		IsMakeup: makeup,
+		Gold:     reward.Gold,
+		Exp:      reward.Exp,
+		Currency: reward.Currency,
+		Amount:   reward.Amount,
+		ItemName: reward.ItemName,
+	}
+	if err := tx.Create(checkin).Error; err != nil {
+		// 唯一索引冲突: 并发重复签到
+		return nil, ErrCheckedIn
+	}
+
+	meta := LogMeta{RefType: RefTypeCheckin, RefID: checkin.ID}
+	if makeup {
+		meta.Description = "补签: " + day
+		if _, err := ChangeGold(tx, userID, -config.AppConfig.Checkin.MakeupCost, meta); err != nil {
+			return nil, err
+		}
+	}
+
+	meta.Description = fmt.Sprintf("签到奖励: 本月第%d天", seq)
+	if err := GrantBundle(tx, userID, &reward.RewardBundle, meta); err != nil {
+		return nil, err
+	}
+	return checkin, nil
+}
+
+// MakeupRemaining 本月剩余补签次数
+func MakeupRemaining(db *gorm.DB, userID uint, month string) int {
+	var used int64
+	db.Model(&models.UserCheckin{}).Where("user_id = ? AND month = ? AND is_makeup = ?", userID, month, true).Count(&used)
+	remaining := config.AppConfig.Checkin.MakeupLimit - int(used)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// GrantBundle 发放组合奖励, 物品奖励以流水关联类型作为来源放入背包
+func GrantBundle(tx *gorm.DB, userID uint, bundle *models.RewardBundle, meta LogMeta) error {
+	if _, err := ChangeGold(tx, userID, bundle.Gold, meta); err != nil {
+		return err
+	}
+	if _, err := ChangeExp(tx, userID, bundle.Exp, meta); err != nil {
+		return err
+	}
+	if bundle.Currency != "" && bundle.Amount > 0 {
+		if _, err := ChangeCurrency(tx, userID, bundle.Currency, bundle.Amount, meta); err != nil {
+			return err
+		}
+	}
+	if bundle.ItemName == "" {
+		return nil
+	}
+	kind := bundle.ItemKind
+	if kind == "" {
+		kind = DropItem
+	}
+	return tx.Create(&models.UserItem{
+		UserID:   userID,
+		Kind:     kind,
+		Name:     bundle.ItemName,
+		Rarity:   bundle.ItemRarity,
+		Source:   meta.RefType,
+		SourceID: meta.RefID,
+	}).Error
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// RefTypeChest 开箱奖励流水的关联类型
+const RefTypeChest = "chest"
+
+// RewardTypeChest 宝箱类奖励
+const RewardTypeChest = "chest"
+
+// 掉落类型
+const (
+	DropCurrency = "currency"
+	DropExp      = "exp"
+	DropReward   = "reward"
+	DropItem     = "item"
+	DropBadge    = "badge"
+)
+
+// ErrChestEmpty 宝箱未配置掉落表
+var ErrChestEmpty = errors.New("宝箱暂未配置掉落内容")
+
+// DropRate 掉落概率公示
+type DropRate struct {
+	models.ChestDrop
+	Probability float64 `json:"probability"` // 百分比
+}
+
+// DropRates 计算掉落表中每项的概率
+func DropRates(drops []models.ChestDrop) []DropRate {
+	total := 0
+	for _, d := range drops {
+		total += d.Weight
+	}
+	rates := make([]DropRate, 0, len(drops))
+	for _, d := range drops {
+		rate := DropRate{ChestDrop: d}
+		if total > 0 {
+			rate.Probability = float64(d.Weight) / float64(total) * 100
+		}
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// LoadDropTable 读取宝箱掉落表, 顺序即抽取时的累计顺序
+func LoadDropTable(db *gorm.DB, chestID uint) []models.ChestDrop {
+	var drops []models.ChestDrop
+	db.Where("chest_id = ? AND weight > 0", chestID).Order("sort, id").Find(&drops)
+	return drops
+}
+
+// ChestRoll 由种子和随机数计算抽取值: HMAC-SHA256(seed, "chestID:userID:nonce") 前8字节对总权重取模
+func ChestRoll(seed, nonce string, chestID, userID uint, totalWeight int) uint64 {
+	mac := hmac.New(sha256.New, []byte(seed))
+	fmt.Fprintf(mac, "%d:%d:%s", chestID, userID, nonce)
+	sum := mac.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]) % uint64(totalWeight)
+}
+
+// pickDrop 按累计权重选出抽取值落入的条目
+func pickDrop(drops []models.ChestDrop, roll uint64) *models.ChestDrop {
+	var cumulative uint64
+	for i := range drops {
+		cumulative += uint64(drops[i].Weight)
+		if roll < cumulative {
+			return &drops[i]
+		}
+	}
+	return nil
+}
+
+// randomHex 生成随机hex字符串
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// OpenChest 开启宝箱: 抽取掉落、保存种子与掉落表快照并发放奖励
+// 调用方需已完成宝箱本身的扣款
+func OpenChest(tx *gorm.DB, userID uint, chest *models.Reward) (*models.ChestOpen, error) {
+	drops := LoadDropTable(tx, chest.ID)
+	total := 0
+	for _, d := range drops {
+		total += d.Weight
+	}
+	if total == 0 {
+		return nil, ErrChestEmpty
+	}
+
+	seed, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, _ := json.Marshal(drops)
+
+	roll := ChestRoll(seed, nonce, chest.ID, userID, total)
+	drop := pickDrop(drops, roll)
+
+	open := &models.ChestOpen{
+		UserID:      userID,
+		ChestID:     chest.ID,
+		Seed:        seed,
+		Nonce:       nonce,
+		Roll:        roll,
+		TotalWeight: total,
+		DropID:      drop.ID,
+		DropName:    drop.Name,
+		Rarity:      drop.Rarity,
+		DropTable:   string(snapshot),
+	}
+	if err := tx.Create(open).Error; err != nil {
+		return nil, err
+	}
+	return open, grantDrop(tx, userID, drop, open.ID, chest.Title)
+}
+
+// grantDrop 发放掉落
+func grantDrop(tx *gorm.DB, userID uint, drop *models.ChestDrop, openID uint, chestTitle string) error {
+	meta := LogMeta{
+		Description: fmt.Sprintf("开启%s: %s", chestTitle, drop.Name),
+		RefType:     RefTypeChest,
+		RefID:       openID,
+	}
+	switch drop.Kind {
+	case DropCurrency:
+		_, err := ChangeCurrency(tx, userID, drop.Currency, drop.Amount, meta)
+		return err
+	case DropExp:
+		_, err := ChangeExp(tx, userID, drop.Amount, meta)
+		return err
+	default:
+		return tx.Create(&models.UserItem{
+			UserID:   userID,
+			Kind:     drop.Kind,
+			Name:     drop.Name,
+			RewardID: drop.ItemRewardID,
+			Rarity:   drop.Rarity,
+			Source:   RefTypeChest,
+			SourceID: openID,
+		}).Error
+	}
+}
+
+// ChestVerification 开箱验证结果
+type ChestVerification struct {
+	Valid  bool   `json:"valid"`
+	Roll   uint64 `json:"roll"`
+	DropID uint   `json:"dropId"`
+}
+
+// VerifyChestOpen 使用记录中的种子和掉落表快照重新计算抽取结果
+func VerifyChestOpen(open *models.ChestOpen) ChestVerification {
+	var drops []models.ChestDrop
+	if err := json.Unmarshal([]byte(open.DropTable), &drops); err != nil {
+		return ChestVerification{}
+	}
+	total := 0
+	for _, d := range drops {
+		total += d.Weight
+	}
+	if total == 0 || total != open.TotalWeight {
+		return ChestVerification{}
+	}
+
+	roll := ChestRoll(open.Seed, open.Nonce, open.ChestID, open.UserID, total)
+	result := ChestVerification{Roll: roll}
+	if drop := pickDrop(drops, roll); drop != nil {
+		result.DropID = drop.ID
+	}
+	result.Valid = roll == open.Roll && result.DropID == open.DropID
+	return result
+}
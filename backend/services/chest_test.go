@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"life-rpg/models"
+)
+
+func TestChestRoll(t *testing.T) {
+	tests := []struct {
+		name            string
+		seed, nonce     string
+		chestID, userID uint
+		totalWeight     int
+		want            uint64
+	}{
+		// HMAC-SHA256("seed", "3:7:nonce") 前8字节对100取模
+		{"已知结果", "seed", "nonce", 3, 7, 100, 43},
+		{"总权重为1", "seed", "nonce", 3, 7, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChestRoll(tt.seed, tt.nonce, tt.chestID, tt.userID, tt.totalWeight)
+			if got != tt.want {
+				t.Errorf("ChestRoll() = %d, want %d", got, tt.want)
+			}
+			if again := ChestRoll(tt.seed, tt.nonce, tt.chestID, tt.userID, tt.totalWeight); again != got {
+				t.Errorf("相同输入结果不一致: %d != %d", again, got)
+			}
+		})
+	}
+}
+
+func TestChestRollRange(t *testing.T) {
+	for _, weight := range []int{1, 2, 7, 100, 10000} {
+		for nonce := 0; nonce < 50; nonce++ {
+			if roll := ChestRoll("seed", strconv.Itoa(nonce), 1, 1, weight); roll >= uint64(weight) {
+				t.Fatalf("ChestRoll() = %d, 超出总权重 %d", roll, weight)
+			}
+		}
+	}
+}
+
+func TestPickDrop(t *testing.T) {
+	drops := []models.ChestDrop{
+		{ID: 1, Weight: 70},
+		{ID: 2, Weight: 25},
+		{ID: 3, Weight: 5},
+	}
+	tests := []struct {
+		name  string
+		drops []models.ChestDrop
+		roll  uint64
+		want  uint // 0表示未选中
+	}{
+		{"第一项起点", drops, 0, 1},
+		{"第一项终点", drops, 69, 1},
+		{"第二项起点", drops, 70, 2},
+		{"第二项终点", drops, 94, 2},
+		{"最后一项", drops, 99, 3},
+		{"超出总权重", drops, 100, 0},
+		{"空掉落表", nil, 0, 0},
+		{"跳过权重为0的条目", []models.ChestDrop{{ID: 1, Weight: 0}, {ID: 2, Weight: 10}}, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint
+			if drop := pickDrop(tt.drops, tt.roll); drop != nil {
+				got = drop.ID
+			}
+			if got != tt.want {
+				t.Errorf("pickDrop(%d) = %d, want %d", tt.roll, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// 动态类型
+const (
+	FeedTask        = "task"
+	FeedLevelUp     = "level_up"
+	FeedAchievement = "achievement"
+	FeedPurchase    = "purchase"
+)
+
+// StreakMilestones 连续完成任务达到这些天数时发布成就动态
+var StreakMilestones = []int{7, 30, 100, 365}
+
+// 动态错误
+var (
+	ErrFeedNotFound     = errors.New("动态不存在")
+	ErrFeedCheered      = errors.New("已为该动态加油")
+	ErrFeedComment      = errors.New("评论内容需在1-500字之间")
+	ErrFeedCommentOwner = errors.New("只能删除自己的评论或自己动态下的评论")
+)
+
+// PublishFeed 发布动态, 与业务流程在同一事务中写入
+func PublishFeed(tx *gorm.DB, userID uint, kind, title, refType string, refID uint) error {
+	return tx.Create(&models.FeedEvent{
+		TenantID: TenantOf(tx, userID),
+		UserID:   userID,
+		Kind:     kind,
+		Title:    title,
+		RefType:  refType,
+		RefID:    refID,
+	}).Error
+}
+
+// publishTaskFeed 发布任务完成、升级与连续打卡成就动态
+func publishTaskFeed(tx *gorm.DB, userID uint, task *models.Task, payout *TaskPayout) error {
+	if err := PublishFeed(tx, userID, FeedTask, "完成了任务「"+task.Title+"」", RefTypeTask, task.ID); err != nil {
+		return err
+	}
+	if payout.Exp != nil && payout.Exp.Level > payout.Exp.PrevLevel {
+		title := fmt.Sprintf("升到了 Lv.%d", payout.Exp.Level)
+		if err := PublishFeed(tx, userID, FeedLevelUp, title, RefTypeTask, task.ID); err != nil {
+			return err
+		}
+	}
+
+	// 每天首次完成任务时检查连续打卡里程碑
+	var today int64
+	tx.Model(&models.UserTask{}).
+		Where("user_id = ? AND completed_at >= ? AND status = ?", userID, payout.UserTask.CompletedAt.Format("2006-01-02"), TaskApproved).
+		Count(&today)
+	if today != 1 {
+		return nil
+	}
+	streak := TaskStreak(tx, userID)
+	for _, milestone := range StreakMilestones {
+		if streak == milestone {
+			title := fmt.Sprintf("连续%d天完成任务", streak)
+			return PublishFeed(tx, userID, FeedAchievement, title, RefTypeTask, task.ID)
+		}
+	}
+	return nil
+}
+
+// CanSeeFeedEvent 查看者能否看到动态: 按发布者的动态可见范围, 升级动态还需等级可见
+func CanSeeFeedEvent(db *gorm.DB, viewerID uint, event *models.FeedEvent) bool {
+	privacy := GetPrivacy(db, event.UserID)
+	if !CanView(db, viewerID, event.UserID, privacy.Feed) {
+		return false
+	}
+	if event.Kind == FeedLevelUp {
+		return CanView(db, viewerID, event.UserID, privacy.Level)
+	}
+	return true
+}
+
+// loadVisibleEvent 读取查看者可见的动态
+func loadVisibleEvent(tx *gorm.DB, viewerID, eventID uint) (*models.FeedEvent, error) {
+	var event models.FeedEvent
+	if err := tx.First(&event, eventID).Error; err != nil || !CanSeeFeedEvent(tx, viewerID, &event) {
+		return nil, ErrFeedNotFound
+	}
+	return &event, nil
+}
+
+// CheerEvent 为动态加油
+func CheerEvent(tx *gorm.DB, userID, eventID uint) error {
+	event, err := loadVisibleEvent(tx, userID, eventID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&models.FeedReaction{EventID: event.ID, UserID: userID}).Error; err != nil {
+		return ErrFeedCheered
+	}
+	return tx.Model(event).Update("cheer_count", gorm.Expr("cheer_count + 1")).Error
+}
+
+// UncheerEvent 取消加油
+func UncheerEvent(tx *gorm.DB, userID, eventID uint) error {
+	result := tx.Where("event_id = ? AND user_id = ?", eventID, userID).Delete(&models.FeedReaction{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return tx.Model(&models.FeedEvent{}).Where("id = ?", eventID).
+		Update("cheer_count", gorm.Expr("cheer_count - 1")).Error
+}
+
+// AddComment 评论动态
+func AddComment(tx *gorm.DB, userID, eventID uint, content string) (*models.FeedComment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || len([]rune(content)) > 500 {
+		return nil, ErrFeedComment
+	}
+	event, err := loadVisibleEvent(tx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	comment := &models.FeedComment{EventID: event.ID, UserID: userID, Content: content}
+	if err := tx.Create(comment).Error; err != nil {
+		return nil, err
+	}
+	return comment, tx.Model(event).Update("comment_count", gorm.Expr("comment_count + 1")).Error
+}
+
+// DeleteComment 删除评论, operatorID 为 0 表示管理员删除
+func DeleteComment(tx *gorm.DB, operatorID, commentID uint) error {
+	var comment models.FeedComment
+	if err := tx.First(&comment, commentID).Error; err != nil {
+		return ErrFeedNotFound
+	}
+	// 动态按空间隔离, 找不到时说明评论不属于当前空间
+	var event models.FeedEvent
+	if err := tx.First(&event, comment.EventID).Error; err != nil {
+		return ErrFeedNotFound
+	}
+	if operatorID != 0 && comment.UserID != operatorID && event.UserID != operatorID {
+		return ErrFeedCommentOwner
+	}
+	if err := tx.Delete(&comment).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.FeedEvent{}).Where("id = ?", comment.EventID).
+		Update("comment_count", gorm.Expr("comment_count - 1")).Error
+}
+
+// purchaseFeedTitle 大额兑换的动态标题, 未达到阈值时返回空
+func purchaseFeedTitle(reward *models.Reward, price int) string {
+	if price < config.AppConfig.Feed.BigPurchase {
+		return ""
+	}
+	return "兑换了「" + reward.Title + "」"
+}
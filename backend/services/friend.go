@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// 好友关系状态
+const (
+	FriendPending  = "pending"
+	FriendAccepted = "accepted"
+)
+
+// 隐私可见范围
+const (
+	VisiblePublic  = "public"
+	VisibleFriends = "friends"
+	VisiblePrivate = "private"
+)
+
+// 好友错误
+var (
+	ErrFriendSelf       = errors.New("不能添加自己为好友")
+	ErrFriendUser       = errors.New("用户不存在或已被禁用")
+	ErrFriendExists     = errors.New("已是好友或申请已发送")
+	ErrFriendBlocked    = errors.New("无法向该用户发送好友申请")
+	ErrFriendRequest    = errors.New("好友申请不存在")
+	ErrFriendNotFound   = errors.New("好友关系不存在")
+	ErrBlockSelf        = errors.New("不能拉黑自己")
+	ErrInvalidPrivacy   = errors.New("可见范围只能是public、friends或private")
+	ErrFriendNotAllowed = errors.New("对方已关闭好友申请")
+)
+
+// friendshipBetween 查询两个用户之间的好友关系(不区分方向)
+func friendshipBetween(db *gorm.DB, a, b uint) (*models.Friendship, error) {
+	var friendship models.Friendship
+	err := db.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)", a, b, b, a).
+		First(&friendship).Error
+	return &friendship, err
+}
+
+// IsBlocked 两个用户之间任意一方拉黑了另一方
+func IsBlocked(db *gorm.DB, a, b uint) bool {
+	var count int64
+	db.Model(&models.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count)
+	return count > 0
+}
+
+// AreFriends 两个用户是否为好友
+func AreFriends(db *gorm.DB, a, b uint) bool {
+	friendship, err := friendshipBetween(db, a, b)
+	return err == nil && friendship.Status == FriendAccepted
+}
+
+// FriendIDs 用户全部好友ID
+func FriendIDs(db *gorm.DB, userID uint) []uint {
+	var friendships []models.Friendship
+	db.Where("(user_id = ? OR friend_id = ?) AND status = ?", userID, userID, FriendAccepted).Find(&friendships)
+	ids := make([]uint, 0, len(friendships))
+	for _, f := range friendships {
+		if f.UserID == userID {
+			ids = append(ids, f.FriendID)
+		} else {
+			ids = append(ids, f.UserID)
+		}
+	}
+	return ids
+}
+
+// SendFriendRequest 发送好友申请, 对方已向自己发出申请时直接成为好友
+func SendFriendRequest(tx *gorm.DB, userID, targetID uint, message string) (*models.Friendship, error) {
+	if userID == targetID {
+		return nil, ErrFriendSelf
+	}
+	var target models.SysUser
+	if err := tx.First(&target, targetID).Error; err != nil || target.Status != 1 {
+		return nil, ErrFriendUser
+	}
+	if IsBlocked(tx, userID, targetID) {
+		return nil, ErrFriendBlocked
+	}
+
+	existing, err := friendshipBetween(tx, userID, targetID)
+	if err == nil {
+		if existing.Status == FriendPending && existing.FriendID == userID {
+			return existing, acceptFriendship(tx, existing)
+		}
+		return nil, ErrFriendExists
+	}
+	if !GetPrivacy(tx, targetID).AllowRequests {
+		return nil, ErrFriendNotAllowed
+	}
+
+	friendship := &models.Friendship{
+		UserID:   userID,
+		FriendID: targetID,
+		Status:   FriendPending,
+		Message:  message,
+	}
+	if err := tx.Create(friendship).Error; err != nil {
+		return nil, ErrFriendExists
+	}
+	return friendship, nil
+}
+
+// RespondFriendRequest 处理收到的好友申请, 拒绝时删除申请
+func RespondFriendRequest(tx *gorm.DB, userID, requestID uint, accept bool) (*models.Friendship, error) {
+	var friendship models.Friendship
+	if err := tx.Where("id = ? AND friend_id = ? AND status = ?", requestID, userID, FriendPending).
+		First(&friendship).Error; err != nil {
+		return nil, ErrFriendRequest
+	}
+	if !accept {
+		return &friendship, tx.Delete(&friendship).Error
+	}
+	return &friendship, acceptFriendship(tx, &friendship)
+}
+
+// acceptFriendship 通过好友申请
+func acceptFriendship(tx *gorm.DB, friendship *models.Friendship) error {
+	now := time.Now()
+	friendship.Status, friendship.AcceptedAt = FriendAccepted, &now
+	return tx.Model(friendship).Updates(map[string]interface{}{
+		"status":      FriendAccepted,
+		"accepted_at": now,
+	}).Error
+}
+
+// RemoveFriend 删除好友或撤回已发出的申请
+func RemoveFriend(tx *gorm.DB, userID, friendID uint) error {
+	result := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID, friendID, friendID, userID).Delete(&models.Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFriendNotFound
+	}
+	return nil
+}
+
+// BlockUser 拉黑用户并解除双方好友关系
+func BlockUser(tx *gorm.DB, userID, targetID uint) error {
+	if userID == targetID {
+		return ErrBlockSelf
+	}
+	var target models.SysUser
+	if err := tx.First(&target, targetID).Error; err != nil {
+		return ErrFriendUser
+	}
+	if err := tx.Where("user_id = ? AND blocked_id = ?", userID, targetID).
+		FirstOrCreate(&models.UserBlock{}, models.UserBlock{UserID: userID, BlockedID: targetID}).Error; err != nil {
+		return err
+	}
+	return tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID, targetID, targetID, userID).Delete(&models.Friendship{}).Error
+}
+
+// GetPrivacy 获取隐私设置, 未设置时返回默认值
+func GetPrivacy(db *gorm.DB, userID uint) *models.UserPrivacy {
+	privacy := &models.UserPrivacy{
+		UserID:        userID,
+		Level:         VisiblePublic,
+		Streak:        VisibleFriends,
+		Logs:          VisiblePrivate,
+		Feed:          VisibleFriends,
+		AllowRequests: true,
+	}
+	db.Where("user_id = ?", userID).First(privacy)
+	return privacy
+}
+
+// CheckVisibility 校验可见范围取值
+func CheckVisibility(values ...string) error {
+	for _, v := range values {
+		if v != VisiblePublic && v != VisibleFriends && v != VisiblePrivate {
+			return ErrInvalidPrivacy
+		}
+	}
+	return nil
+}
+
+// CanView viewer 能否查看 owner 设置为 visibility 的信息
+func CanView(db *gorm.DB, viewerID, ownerID uint, visibility string) bool {
+	if viewerID == ownerID {
+		return true
+	}
+	if IsBlocked(db, viewerID, ownerID) {
+		return false
+	}
+	switch visibility {
+	case VisiblePublic:
+		return true
+	case VisibleFriends:
+		return AreFriends(db, viewerID, ownerID)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefTypeGoal 心愿储蓄流水的关联类型
+const RefTypeGoal = "goal"
+
+// 心愿状态
+const (
+	GoalActive    = "active"
+	GoalPurchased = "purchased"
+	GoalCancelled = "cancelled"
+)
+
+// 心愿错误
+var (
+	ErrGoalNotActive   = errors.New("心愿已结束")
+	ErrGoalNotFunded   = errors.New("储蓄不足以兑换")
+	ErrGoalOverSaved   = errors.New("储蓄金额不足")
+	ErrAutoPercentOver = errors.New("自动储蓄比例合计不能超过100%")
+)
+
+// LockGoal 在事务内以行锁读取用户的心愿
+func LockGoal(tx *gorm.DB, userID, goalID uint) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Reward", Unscoped).
+		Where("id = ? AND user_id = ?", goalID, userID).
+		First(&goal).Error; err != nil {
+		return nil, err
+	}
+	if goal.Status != GoalActive {
+		return &goal, ErrGoalNotActive
+	}
+	return &goal, nil
+}
+
+// Unscoped 预加载时包含已删除的记录, 用于展示已下架的任务、奖励等
+func Unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// CheckAutoPercent 校验用户所有进行中心愿的自动储蓄比例合计不超过100%
+func CheckAutoPercent(db *gorm.DB, userID, excludeGoalID uint, percent int) error {
+	if percent < 0 || percent > 100 {
+		return ErrAutoPercentOver
+	}
+	var total int
+	db.Model(&models.SavingsGoal{}).
+		Where("user_id = ? AND status = ? AND id <> ?", userID, GoalActive, excludeGoalID).
+		Select("COALESCE(SUM(auto_percent), 0)").
+		Scan(&total)
+	if total+percent > 100 {
+		return ErrAutoPercentOver
+	}
+	return nil
+}
+
+// DepositGoal 从余额存入心愿，amount 为负数时表示取出
+func DepositGoal(tx *gorm.DB, goal *models.SavingsGoal, amount int, description string) error {
+	if goal.Saved+amount < 0 {
+		return ErrGoalOverSaved
+	}
+	if _, err := ChangeCurrency(tx, goal.UserID, goal.Currency, -amount, LogMeta{
+		Description: description,
+		RefType:     RefTypeGoal,
+		RefID:       goal.ID,
+	}); err != nil {
+		return err
+	}
+	goal.Saved += amount
+	return tx.Model(goal).Update("saved", goal.Saved).Error
+}
+
+// AutoSave 完成任务获得收入后按比例自动存入心愿, 存满为止
+func AutoSave(tx *gorm.DB, userID uint, currency string, earned int) error {
+	if earned <= 0 {
+		return nil
+	}
+
+	var goals []models.SavingsGoal
+	tx.Preload("Reward", Unscoped).
+		Where("user_id = ? AND currency = ? AND status = ? AND auto_percent > 0", userID, currency, GoalActive).
+		Order("id").
+		Find(&goals)
+	for i := range goals {
+		goal := &goals[i]
+		if goal.Reward == nil {
+			continue
+		}
+		amount := earned * goal.AutoPercent / 100
+		if remain := goal.Reward.Cost - goal.Saved; amount > remain {
+			amount = remain
+		}
+		if amount <= 0 {
+			continue
+		}
+		if err := DepositGoal(tx, goal, amount, fmt.Sprintf("自动存入心愿: %s (%d%%)", goal.Reward.Title, goal.AutoPercent)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurchaseGoal 使用心愿储蓄兑换奖励
+// 储蓄先全部转回余额再按正常兑换扣款，保证兑换流水可被退款，多存部分留在余额中
+func PurchaseGoal(tx *gorm.DB, goal *models.SavingsGoal) (int, error) {
+	if goal.Reward == nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if goal.Saved < goal.Reward.Cost {
+		return 0, ErrGoalNotFunded
+	}
+	if err := DepositGoal(tx, goal, -goal.Saved, "心愿达成, 储蓄转出: "+goal.Reward.Title); err != nil {
+		return 0, err
+	}
+	balance, _, err := BuyReward(tx, goal.UserID, goal.Reward, goal.Reward.Cost, PurchaseMeta(goal.Reward, "兑换奖励: "+goal.Reward.Title+" (心愿储蓄)"))
+	if err != nil {
+		return 0, err
+	}
+	goal.Status = GoalPurchased
+	return balance, tx.Model(goal).Update("status", GoalPurchased).Error
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefTypeGuild 公会周目标奖励流水的关联类型
+const RefTypeGuild = "guild"
+
+// 公会角色
+const (
+	GuildLeader  = "leader"
+	GuildOfficer = "officer"
+	GuildMember  = "member"
+)
+
+// 公会错误
+var (
+	ErrGuildJoined       = errors.New("已加入公会")
+	ErrGuildNotMember    = errors.New("未加入公会")
+	ErrGuildNotFound     = errors.New("公会不存在")
+	ErrGuildClosed       = errors.New("该公会不允许自由加入")
+	ErrGuildFull         = errors.New("公会成员已满")
+	ErrGuildPermission   = errors.New("没有权限执行该操作")
+	ErrGuildLeaderLeave  = errors.New("会长需先转让会长后才能退出")
+	ErrGuildRole         = errors.New("角色只能是leader、officer或member")
+	ErrGuildNameExists   = errors.New("公会名称已存在")
+	ErrGuildTargetMember = errors.New("该用户不是本公会成员")
+	ErrGuildGoalReward   = errors.New("周目标奖励超出上限")
+	ErrGuildGoalTarget   = errors.New("设置奖励时周目标不能低于成员数对应的最低目标")
+)
+
+// GuildWeekKey 周目标的周标识, 按ISO周计算
+func GuildWeekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// MembershipOf 用户的公会成员信息
+func MembershipOf(db *gorm.DB, userID uint) (*models.GuildMember, error) {
+	var member models.GuildMember
+	if err := db.Where("user_id = ?", userID).First(&member).Error; err != nil {
+		return nil, ErrGuildNotMember
+	}
+	return &member, nil
+}
+
+// lockGuild 以行锁读取公会
+func lockGuild(tx *gorm.DB, guildID uint) (*models.Guild, error) {
+	var guild models.Guild
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&guild, guildID).Error; err != nil {
+		return nil, ErrGuildNotFound
+	}
+	return &guild, nil
+}
+
+// CreateGuild 创建公会, 创建者成为会长
+func CreateGuild(tx *gorm.DB, userID uint, guild *models.Guild) error {
+	if _, err := MembershipOf(tx, userID); err == nil {
+		return ErrGuildJoined
+	}
+	var count int64
+	tx.Model(&models.Guild{}).Unscoped().Where("name = ?", guild.Name).Count(&count)
+	if count > 0 {
+		return ErrGuildNameExists
+	}
+
+	guild.ID = 0
+	guild.LeaderID = userID
+	guild.MemberCount = 1
+	if err := tx.Create(guild).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.GuildMember{
+		GuildID:  guild.ID,
+		UserID:   userID,
+		Role:     GuildLeader,
+		JoinedAt: time.Now(),
+	}).Error
+}
+
+// JoinGuild 加入公会
+func JoinGuild(tx *gorm.DB, userID, guildID uint) error {
+	if _, err := MembershipOf(tx, userID); err == nil {
+		return ErrGuildJoined
+	}
+	guild, err := lockGuild(tx, guildID)
+	if err != nil {
+		return err
+	}
+	if !guild.IsOpen {
+		return ErrGuildClosed
+	}
+	if guild.MemberCount >= guild.MemberLimit {
+		return ErrGuildFull
+	}
+
+	if err := tx.Create(&models.GuildMember{
+		GuildID:  guild.ID,
+		UserID:   userID,
+		Role:     GuildMember,
+		JoinedAt: time.Now(),
+	}).Error; err != nil {
+		return ErrGuildJoined
+	}
+	return tx.Model(guild).Update("member_count", gorm.Expr("member_count + 1")).Error
+}
+
+// LeaveGuild 退出公会, 会长是最后一名成员时解散公会
+func LeaveGuild(tx *gorm.DB, userID uint) error {
+	member, err := MembershipOf(tx, userID)
+	if err != nil {
+		return err
+	}
+	guild, err := lockGuild(tx, member.GuildID)
+	if err != nil {
+		return err
+	}
+	if member.Role == GuildLeader {
+		if guild.MemberCount > 1 {
+			return ErrGuildLeaderLeave
+		}
+		if err := tx.Delete(member).Error; err != nil {
+			return err
+		}
+		return tx.Delete(guild).Error
+	}
+	return removeMember(tx, guild, member)
+}
+
+// KickMember 移出成员: 会长可移出任何成员, 官员只能移出普通成员
+func KickMember(tx *gorm.DB, operatorID, targetID uint) error {
+	operator, err := MembershipOf(tx, operatorID)
+	if err != nil {
+		return err
+	}
+	target, err := MembershipOf(tx, targetID)
+	if err != nil || target.GuildID != operator.GuildID {
+		return ErrGuildTargetMember
+	}
+	if operatorID == targetID || !outranks(operator.Role, target.Role) {
+		return ErrGuildPermission
+	}
+	guild, err := lockGuild(tx, operator.GuildID)
+	if err != nil {
+		return err
+	}
+	return removeMember(tx, guild, target)
+}
+
+// SetMemberRole 会长调整成员角色, 设为 leader 时转让会长, 原会长成为官员
+func SetMemberRole(tx *gorm.DB, operatorID, targetID uint, role string) error {
+	if role != GuildLeader && role != GuildOfficer && role != GuildMember {
+		return ErrGuildRole
+	}
+	operator, err := MembershipOf(tx, operatorID)
+	if err != nil {
+		return err
+	}
+	if operator.Role != GuildLeader || operatorID == targetID {
+		return ErrGuildPermission
+	}
+	target, err := MembershipOf(tx, targetID)
+	if err != nil || target.GuildID != operator.GuildID {
+		return ErrGuildTargetMember
+	}
+
+	if err := tx.Model(target).Update("role", role).Error; err != nil {
+		return err
+	}
+	if role != GuildLeader {
+		return nil
+	}
+	if err := tx.Model(operator).Update("role", GuildOfficer).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Guild{}).Where("id = ?", operator.GuildID).Update("leader_id", targetID).Error
+}
+
+// CanManageGuild 会长和官员可修改公会资料与周目标
+func CanManageGuild(member *models.GuildMember) bool {
+	return member.Role == GuildLeader || member.Role == GuildOfficer
+}
+
+// outranks 角色 a 是否高于角色 b
+func outranks(a, b string) bool {
+	rank := map[string]int{GuildMember: 1, GuildOfficer: 2, GuildLeader: 3}
+	return rank[a] > rank[b]
+}
+
+// removeMember 删除成员并更新人数
+func removeMember(tx *gorm.DB, guild *models.Guild, member *models.GuildMember) error {
+	if err := tx.Delete(member).Error; err != nil {
+		return err
+	}
+	return tx.Model(guild).Update("member_count", gorm.Expr("member_count - 1")).Error
+}
+
+// MinGuildGoalTarget 公会设置周目标奖励时的最低目标, 随成员数增加
+func MinGuildGoalTarget(members int) int {
+	if members < 1 {
+		members = 1
+	}
+	return members * config.AppConfig.Guild.MinTargetPerMember
+}
+
+// CheckGuildGoal 校验周目标与奖励: 奖励不超过配置上限, 有奖励时目标不低于最低目标
+func CheckGuildGoal(target, gold, exp, members int) error {
+	cfg := config.AppConfig.Guild
+	if gold > cfg.MaxGoalGold || exp > cfg.MaxGoalExp {
+		return ErrGuildGoalReward
+	}
+	if (gold > 0 || exp > 0) && target < MinGuildGoalTarget(members) {
+		return ErrGuildGoalTarget
+	}
+	return nil
+}
+
+// guildGoalTarget 本周实际需达成的目标, 成员增加后不低于按当前成员数计算的最低目标
+func guildGoalTarget(guild *models.Guild, gw *models.GuildWeek) int {
+	if gw.Gold <= 0 && gw.Exp <= 0 {
+		return gw.Target
+	}
+	if minTarget := MinGuildGoalTarget(guild.MemberCount); gw.Target < minTarget {
+		return minTarget
+	}
+	return gw.Target
+}
+
+// lockGuildWeek 以行锁读取公会本周目标, 不存在时按公会当前设置创建
+func lockGuildWeek(tx *gorm.DB, guild *models.Guild, week string) (*models.GuildWeek, error) {
+	var gw models.GuildWeek
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("guild_id = ? AND week = ?", guild.ID, week).
+		Attrs(models.GuildWeek{Target: guild.GoalTarget, Gold: guild.GoalGold, Exp: guild.GoalExp}).
+		FirstOrCreate(&gw, models.GuildWeek{GuildID: guild.ID, Week: week}).Error
+	return &gw, err
+}
+
+// CurrentGuildWeek 公会本周目标进度(只读)
+func CurrentGuildWeek(db *gorm.DB, guild *models.Guild) *models.GuildWeek {
+	gw := &models.GuildWeek{
+		GuildID: guild.ID,
+		Week:    GuildWeekKey(time.Now()),
+		Target:  guild.GoalTarget,
+		Gold:    guild.GoalGold,
+		Exp:     guild.GoalExp,
+	}
+	db.Where("guild_id = ? AND week = ?", guild.ID, gw.Week).First(gw)
+	return gw
+}
+
+// AddGuildProgress 成员完成任务时累加公会周目标进度, 达成目标时记录完成时间, 奖励由定时任务结算
+// 用户未加入公会时返回 nil
+func AddGuildProgress(tx *gorm.DB, userID uint) (*models.GuildWeek, error) {
+	member, err := MembershipOf(tx, userID)
+	if err != nil {
+		return nil, nil
+	}
+	var guild models.Guild
+	if err := tx.First(&guild, member.GuildID).Error; err != nil {
+		return nil, nil
+	}
+
+	gw, err := lockGuildWeek(tx, &guild, GuildWeekKey(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	gw.Progress++
+	updates := map[string]interface{}{"progress": gw.Progress}
+	if gw.CompletedAt == nil && gw.Target > 0 && gw.Progress >= guildGoalTarget(&guild, gw) {
+		now := time.Now()
+		gw.CompletedAt = &now
+		updates["completed_at"] = now
+	}
+	return gw, tx.Model(gw).Updates(updates).Error
+}
+
+// SettleGuildWeeks 结算已达成但未发放奖励的公会周目标
+// 在完成任务的事务之外发放, 避免持有周目标行锁时再锁定全体成员
+func SettleGuildWeeks(db *gorm.DB) error {
+	var weeks []models.GuildWeek
+	if err := db.Where("completed_at IS NOT NULL AND paid_at IS NULL").Find(&weeks).Error; err != nil {
+		return err
+	}
+	for i := range weeks {
+		if err := payGuildGoal(db, &weeks[i]); err != nil {
+			return fmt.Errorf("结算公会周目标 %d 失败: %w", weeks[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// payGuildGoal 向本周开始前已加入的成员发放周目标奖励
+// 按用户ID顺序逐个在独立事务中发放, 已有本周目标奖励流水的成员跳过, 重复结算不会重复发放
+func payGuildGoal(db *gorm.DB, gw *models.GuildWeek) error {
+	var guild models.Guild
+	if err := db.First(&guild, gw.GuildID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 公会已解散, 没有可发放的成员
+			return db.Model(gw).Update("paid_at", time.Now()).Error
+		}
+		return err
+	}
+	var userIDs []uint
+	if err := db.Model(&models.GuildMember{}).
+		Where("guild_id = ? AND joined_at < ?", guild.ID, weekStartOf(*gw.CompletedAt)).
+		Order("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	meta := LogMeta{
+		Description: fmt.Sprintf("公会「%s」达成周目标 %s", guild.Name, gw.Week),
+		RefType:     RefTypeGuild,
+		RefID:       gw.ID,
+	}
+	// 按当前上限发放, 上限调低前设置的奖励同样受限
+	cfg := config.AppConfig.Guild
+	gold, exp := gw.Gold, gw.Exp
+	if gold > cfg.MaxGoalGold {
+		gold = cfg.MaxGoalGold
+	}
+	if exp > cfg.MaxGoalExp {
+		exp = cfg.MaxGoalExp
+	}
+	for _, id := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if _, err := LockUser(tx, id); err != nil {
+				return err
+			}
+			var paid int64
+			tx.Model(&models.UserLog{}).
+				Where("user_id = ? AND ref_type = ? AND ref_id = ?", id, RefTypeGuild, gw.ID).
+				Count(&paid)
+			if paid > 0 {
+				return nil
+			}
+			if _, err := ChangeGold(tx, id, gold, meta); err != nil {
+				return err
+			}
+			_, err := ChangeExp(tx, id, exp, meta)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return db.Model(gw).Updates(map[string]interface{}{
+		"paid_at":    time.Now(),
+		"paid_count": len(userIDs),
+	}).Error
+}
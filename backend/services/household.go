@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleGuardian 监护人角色Key
+const RoleGuardian = "guardian"
+
+// 家庭成员角色
+const (
+	HouseholdGuardian = "guardian"
+	HouseholdChild    = "child"
+)
+
+// 任务完成记录状态
+const (
+	TaskApproved = "approved"
+	TaskPending  = "pending"
+	TaskRejected = "rejected"
+)
+
+// 家庭错误
+var (
+	ErrHouseholdNone      = errors.New("尚未创建家庭")
+	ErrHouseholdExists    = errors.New("已创建或加入家庭")
+	ErrHouseholdJoined    = errors.New("该用户已加入其他家庭")
+	ErrHouseholdNotMember = errors.New("该用户不是本家庭成员")
+	ErrHouseholdOwner     = errors.New("不能移除家庭创建者")
+	ErrReviewNotFound     = errors.New("待审核记录不存在")
+	ErrHouseholdSelfTask  = errors.New("监护人不能完成本家庭的任务")
+)
+
+// HouseholdOf 用户所在家庭的成员信息
+func HouseholdOf(db *gorm.DB, userID uint) (*models.HouseholdMember, error) {
+	var member models.HouseholdMember
+	if err := db.Where("user_id = ?", userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GuardianHousehold 监护人创建的家庭
+func GuardianHousehold(db *gorm.DB, guardianID uint) (*models.Household, error) {
+	var household models.Household
+	if err := db.Where("owner_id = ?", guardianID).First(&household).Error; err != nil {
+		return nil, ErrHouseholdNone
+	}
+	return &household, nil
+}
+
+// HouseholdScope 用户可见的任务/奖励范围: 全站数据和所在家庭的数据
+func HouseholdScope(db *gorm.DB, userID uint) func(*gorm.DB) *gorm.DB {
+	ids := []uint{0}
+	if member, err := HouseholdOf(db, userID); err == nil {
+		ids = append(ids, member.HouseholdID)
+	}
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where("household_id IN ?", ids)
+	}
+}
+
+// visibleTasks 用户可见的任务: 所在空间的公共任务和所在家庭的任务, 供没有请求上下文的定时任务使用
+func visibleTasks(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.Task{}).Scopes(HouseholdScope(db, userID)).
+		Where("tenant_id = ?", TenantOf(db, userID))
+}
+
+// HouseholdMemberIDs 家庭全部成员的用户ID
+func HouseholdMemberIDs(db *gorm.DB, householdID uint) []uint {
+	var ids []uint
+	db.Model(&models.HouseholdMember{}).Where("household_id = ?", householdID).Pluck("user_id", &ids)
+	return ids
+}
+
+// CreateHousehold 创建家庭, 创建者以监护人身份加入
+func CreateHousehold(tx *gorm.DB, guardianID uint, name string) (*models.Household, error) {
+	if _, err := HouseholdOf(tx, guardianID); err == nil {
+		return nil, ErrHouseholdExists
+	}
+	household := &models.Household{Name: name, OwnerID: guardianID}
+	if err := tx.Create(household).Error; err != nil {
+		return nil, err
+	}
+	return household, tx.Create(&models.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      guardianID,
+		Role:        HouseholdGuardian,
+		JoinedAt:    time.Now(),
+	}).Error
+}
+
+// AddHouseholdMember 将用户加入家庭, 每个用户只能属于一个家庭
+func AddHouseholdMember(tx *gorm.DB, household *models.Household, userID uint, role string) (*models.HouseholdMember, error) {
+	if role != HouseholdGuardian {
+		role = HouseholdChild
+	}
+	if _, err := HouseholdOf(tx, userID); err == nil {
+		return nil, ErrHouseholdJoined
+	}
+	member := &models.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+	return member, tx.Create(member).Error
+}
+
+// RemoveHouseholdMember 将成员移出家庭
+func RemoveHouseholdMember(tx *gorm.DB, household *models.Household, userID uint) error {
+	if userID == household.OwnerID {
+		return ErrHouseholdOwner
+	}
+	result := tx.Where("household_id = ? AND user_id = ?", household.ID, userID).Delete(&models.HouseholdMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHouseholdNotMember
+	}
+	return nil
+}
+
+// IsHouseholdMember 用户是否属于该家庭
+func IsHouseholdMember(db *gorm.DB, householdID, userID uint) bool {
+	var count int64
+	db.Model(&models.HouseholdMember{}).Where("household_id = ? AND user_id = ?", householdID, userID).Count(&count)
+	return count > 0
+}
+
+// ReviewTask 监护人审核家庭成员提交的任务完成记录, 通过后按正常流程发放奖励
+func ReviewTask(tx *gorm.DB, household *models.Household, reviewerID, userTaskID uint, approve bool) (*models.UserTask, *TaskPayout, error) {
+	var userTask models.UserTask
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status = ?", TaskPending).
+		First(&userTask, userTaskID).Error; err != nil {
+		return nil, nil, ErrReviewNotFound
+	}
+	var task models.Task
+	if err := tx.Unscoped().Preload("CurrencyRewards").First(&task, userTask.TaskID).Error; err != nil ||
+		task.HouseholdID != household.ID {
+		return nil, nil, ErrReviewNotFound
+	}
+
+	status := TaskRejected
+	if approve {
+		status = TaskApproved
+	}
+	now := time.Now()
+	if err := tx.Model(&userTask).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewer_id": reviewerID,
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return nil, nil, err
+	}
+	notice := &models.Notification{
+		UserID:   userTask.UserID,
+		Type:     NotifyTaskReview,
+		Link:     "/tasks",
+		SenderID: reviewerID,
+		RefType:  RefTypeTask,
+		RefID:    task.ID,
+	}
+	if !approve {
+		notice.Title = "任务被驳回"
+		notice.Body = fmt.Sprintf("「%s」未通过审核, 可以重新完成后提交", task.Title)
+		return &userTask, nil, Notify(tx, notice)
+	}
+
+	payout := &TaskPayout{
+		UserTask:        userTask,
+		CurrencyRewards: map[string]int{},
+		Converted:       map[string]int{},
+	}
+	if err := PayTaskReward(tx, userTask.UserID, &task, payout); err != nil {
+		return nil, nil, err
+	}
+	notice.Title = "任务审核通过"
+	notice.Body = fmt.Sprintf("「%s」已通过审核, 获得 %d 金币、%d 经验", task.Title, payout.GoldReward, payout.ExpReward)
+	return &userTask, payout, Notify(tx, notice)
+}
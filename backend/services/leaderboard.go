@@ -0,0 +1,228 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// 排行榜指标
+const (
+	MetricExp    = "exp"
+	MetricTasks  = "tasks"
+	MetricStreak = "streak"
+)
+
+// 排行榜周期
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodAll     = "all"
+)
+
+// 排行榜范围
+const (
+	ScopeGlobal  = "global"
+	ScopeFriends = "friends"
+	ScopeGuild   = "guild"
+)
+
+// LeaderboardMetrics 全部指标
+var LeaderboardMetrics = []string{MetricExp, MetricTasks, MetricStreak}
+
+// LeaderboardPeriods 全部周期
+var LeaderboardPeriods = []string{PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAll}
+
+// ErrLeaderboardParam 排行榜参数错误
+var ErrLeaderboardParam = errors.New("排行榜指标、周期或范围不正确")
+
+// PeriodWindow 周期的起始时间与快照标识, 全部周期起始时间为零值
+func PeriodWindow(period string, now time.Time) (time.Time, string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case PeriodDaily:
+		return today, today.Format("2006-01-02"), nil
+	case PeriodWeekly:
+		offset := (int(today.Weekday()) + 6) % 7 // 周一为一周开始
+		return today.AddDate(0, 0, -offset), GuildWeekKey(today), nil
+	case PeriodMonthly:
+		return today.AddDate(0, 0, 1-today.Day()), today.Format("2006-01"), nil
+	case PeriodAll:
+		return time.Time{}, PeriodAll, nil
+	default:
+		return time.Time{}, "", ErrLeaderboardParam
+	}
+}
+
+// leaderboardValue 单个用户的指标值
+type leaderboardValue struct {
+	UserID uint
+	Value  int
+}
+
+// RefreshLeaderboards 重新计算当前各周期全部指标的排行榜快照
+func RefreshLeaderboards(db *gorm.DB) error {
+	now := time.Now()
+	var activeIDs []uint
+	db.Model(&models.SysUser{}).Where("status = ?", 1).Pluck("id", &activeIDs)
+	active := make(map[uint]bool, len(activeIDs))
+	for _, id := range activeIDs {
+		active[id] = true
+	}
+
+	for _, period := range LeaderboardPeriods {
+		since, key, _ := PeriodWindow(period, now)
+		for _, metric := range LeaderboardMetrics {
+			values, err := computeMetric(db, metric, since)
+			if err != nil {
+				return err
+			}
+			if err := saveSnapshot(db, metric, period, key, values, active); err != nil {
+				return fmt.Errorf("排行榜 %s/%s 计算失败: %w", metric, period, err)
+			}
+		}
+	}
+	return nil
+}
+
+// computeMetric 统计窗口内各用户的指标值
+func computeMetric(db *gorm.DB, metric string, since time.Time) ([]leaderboardValue, error) {
+	var values []leaderboardValue
+	switch metric {
+	case MetricExp:
+		// 只统计实际获得的经验, 排除管理员调整与对账修正
+		err := db.Model(&models.UserLog{}).
+			Select("user_id, SUM(amount) AS value").
+			Where("type = ? AND created_at >= ? AND ref_type NOT IN ?", LogExpIn, since,
+				[]string{"admin", RefTypeReconcile}).
+			Group("user_id").
+			Scan(&values).Error
+		return values, err
+	case MetricTasks:
+		err := db.Model(&models.UserTask{}).
+			Select("user_id, COUNT(*) AS value").
+			Where("completed_at >= ? AND status = ?", since, TaskApproved).
+			Group("user_id").
+			Scan(&values).Error
+		return values, err
+	case MetricStreak:
+		return longestStreaks(db, since)
+	}
+	return nil, ErrLeaderboardParam
+}
+
+// longestStreaks 窗口内各用户连续完成任务的最长天数
+func longestStreaks(db *gorm.DB, since time.Time) ([]leaderboardValue, error) {
+	var rows []struct {
+		UserID uint
+		Day    string
+	}
+	if err := db.Raw("SELECT DISTINCT user_id, DATE_FORMAT(completed_at, '%Y-%m-%d') AS day FROM user_task "+
+		"WHERE completed_at >= ? AND status = ? ORDER BY user_id, day", since, TaskApproved).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var values []leaderboardValue
+	var prev time.Time
+	current := leaderboardValue{}
+	run := 0
+	for _, row := range rows {
+		day, err := time.ParseInLocation("2006-01-02", row.Day, time.Local)
+		if err != nil {
+			continue
+		}
+		if row.UserID != current.UserID {
+			if current.UserID != 0 {
+				values = append(values, current)
+			}
+			current, run = leaderboardValue{UserID: row.UserID}, 0
+		}
+		if run > 0 && day.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > current.Value {
+			current.Value = run
+		}
+		prev = day
+	}
+	if current.UserID != 0 {
+		values = append(values, current)
+	}
+	return values, nil
+}
+
+// saveSnapshot 按指标值排序并整体替换该周期的快照, 并列时按用户ID先后
+func saveSnapshot(db *gorm.DB, metric, period, key string, values []leaderboardValue, active map[uint]bool) error {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Value != values[j].Value {
+			return values[i].Value > values[j].Value
+		}
+		return values[i].UserID < values[j].UserID
+	})
+
+	entries := make([]models.LeaderboardEntry, 0, len(values))
+	for _, v := range values {
+		if !active[v.UserID] || v.Value <= 0 {
+			continue
+		}
+		entries = append(entries, models.LeaderboardEntry{
+			Metric:    metric,
+			Period:    period,
+			PeriodKey: key,
+			UserID:    v.UserID,
+			Value:     v.Value,
+			Rank:      len(entries) + 1,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("metric = ? AND period = ? AND period_key = ?", metric, period, key).
+			Delete(&models.LeaderboardEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(entries, 500).Error
+	})
+}
+
+// ScopeUserIDs 排行榜范围内的用户ID, 全站范围返回 nil
+func ScopeUserIDs(db *gorm.DB, userID uint, scope string) ([]uint, error) {
+	switch scope {
+	case ScopeGlobal:
+		return nil, nil
+	case ScopeFriends:
+		return append(FriendIDs(db, userID), userID), nil
+	case ScopeGuild:
+		member, err := MembershipOf(db, userID)
+		if err != nil {
+			return nil, err
+		}
+		var ids []uint
+		db.Model(&models.GuildMember{}).Where("guild_id = ?", member.GuildID).Pluck("user_id", &ids)
+		return ids, nil
+	default:
+		return nil, ErrLeaderboardParam
+	}
+}
+
+// LeaderboardVisibility 指标对应的隐私设置项, 经验随等级公开范围展示
+func LeaderboardVisibility(privacy *models.UserPrivacy, metric string) string {
+	switch metric {
+	case MetricExp:
+		return privacy.Level
+	case MetricStreak:
+		return privacy.Streak
+	default:
+		return VisiblePublic
+	}
+}
@@ -0,0 +1,284 @@
+// Package services 业务服务层，封装多个控制器共用的账务逻辑
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"life-rpg/models"
+	"life-rpg/realtime"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 币种
+const (
+	CurrencyGold = "gold"
+	CurrencyExp  = "exp" // 经验不属于货币，仅用于流水标记
+)
+
+// 流水类型，货币流水统一为 <币种>_in / <币种>_out
+const (
+	LogGoldIn  = "gold_in"
+	LogGoldOut = "gold_out"
+	LogExpIn   = "exp_in"
+	LogExpOut  = "exp_out"
+)
+
+// InternalRefTypes 只在账户之间或账户内部转移、不产生也不消耗货币的流水关联类型，
+// 统计货币产出与消耗时需排除
+var InternalRefTypes = []string{RefTypeTransfer, RefTypeGoal, RefTypeBank, RefTypeReconcile, RefTypeChallenge}
+
+// 账务错误
+var (
+	ErrInsufficientGold    = errors.New("金币不足")
+	ErrInsufficientBalance = errors.New("余额不足")
+	ErrInsufficientExp     = errors.New("经验不足")
+	ErrUnknownCurrency     = errors.New("币种不存在")
+)
+
+// LogMeta 流水附加信息
+type LogMeta struct {
+	Description string
+	RefType     string // task/reward/admin/refund
+	RefID       uint
+	OperatorID  uint // 操作人, 管理员调整时记录
+}
+
+// ExpResult 经验变动结果
+type ExpResult struct {
+	Exp       int `json:"exp"`
+	Level     int `json:"level"`
+	PrevLevel int `json:"prevLevel"`
+}
+
+// LogTypeIn 币种收入流水类型
+func LogTypeIn(currency string) string {
+	return currency + "_in"
+}
+
+// LogTypeOut 币种支出流水类型
+func LogTypeOut(currency string) string {
+	return currency + "_out"
+}
+
+// LockUser 在事务内以行锁读取用户，避免并发修改余额
+func LockUser(tx *gorm.DB, userID uint) (*models.SysUser, error) {
+	var user models.SysUser
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// TenantOf 用户所属空间, 供定时任务等没有请求上下文的场景写入空间字段
+func TenantOf(db *gorm.DB, userID uint) uint {
+	var tenantIDs []uint
+	db.Model(&models.SysUser{}).Where("id = ?", userID).Pluck("tenant_id", &tenantIDs)
+	if len(tenantIDs) == 0 {
+		return 0
+	}
+	return tenantIDs[0]
+}
+
+// ChangeGold 变动用户金币并写入流水
+// delta 为正表示收入、为负表示支出，余额不足时返回 ErrInsufficientGold
+func ChangeGold(tx *gorm.DB, userID uint, delta int, meta LogMeta) (int, error) {
+	return ChangeCurrency(tx, userID, CurrencyGold, delta, meta)
+}
+
+// ChangeCurrency 变动用户指定币种余额并写入流水
+// 金币余额保存在 SysUser.Gold，其他币种保存在 UserWallet
+func ChangeCurrency(tx *gorm.DB, userID uint, currency string, delta int, meta LogMeta) (int, error) {
+	balance, _, err := changeCurrency(tx, userID, currency, delta, meta)
+	return balance, err
+}
+
+// changeCurrency 变动余额并返回写入的流水, 变动为0时不写流水
+func changeCurrency(tx *gorm.DB, userID uint, currency string, delta int, meta LogMeta) (int, *models.UserLog, error) {
+	if currency == "" {
+		currency = CurrencyGold
+	}
+	user, err := LockUser(tx, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var balance int
+	var wallet models.UserWallet
+	if currency == CurrencyGold {
+		balance = user.Gold
+	} else {
+		if err := checkCurrency(tx, currency); err != nil {
+			return 0, nil, err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND currency = ?", userID, currency).
+			FirstOrCreate(&wallet, models.UserWallet{UserID: userID, Currency: currency}).Error; err != nil {
+			return 0, nil, err
+		}
+		balance = wallet.Balance
+	}
+	if delta == 0 {
+		return balance, nil, nil
+	}
+
+	newBalance := balance + delta
+	if newBalance < 0 {
+		if currency == CurrencyGold {
+			return balance, nil, ErrInsufficientGold
+		}
+		return balance, nil, ErrInsufficientBalance
+	}
+	if currency == CurrencyGold {
+		err = tx.Model(user).Update("gold", newBalance).Error
+	} else {
+		err = tx.Model(&wallet).Update("balance", newBalance).Error
+	}
+	if err != nil {
+		return balance, nil, err
+	}
+
+	logType, amount := LogTypeIn(currency), delta
+	if delta < 0 {
+		logType, amount = LogTypeOut(currency), -delta
+	}
+	realtime.PublishAfterCommit(tx, realtime.UserTopic(userID), realtime.Event{
+		Type: realtime.EventBalance,
+		Data: map[string]interface{}{"currency": currency, "balance": newBalance, "delta": delta},
+	})
+	log, err := writeLog(tx, user, currency, logType, amount, newBalance, meta)
+	return newBalance, log, err
+}
+
+// CheckCurrency 校验币种可用于发放, 空币种按金币处理
+func CheckCurrency(db *gorm.DB, currency string) error {
+	if currency == "" || currency == CurrencyGold {
+		return nil
+	}
+	return checkCurrency(db, currency)
+}
+
+// checkCurrency 校验币种是否存在且启用
+func checkCurrency(tx *gorm.DB, currency string) error {
+	var count int64
+	tx.Model(&models.Currency{}).Where("code = ? AND is_active = ?", currency, true).Count(&count)
+	if count == 0 {
+		return ErrUnknownCurrency
+	}
+	return nil
+}
+
+// Balances 获取用户全部币种余额
+func Balances(db *gorm.DB, userID uint) (map[string]int, error) {
+	var user models.SysUser
+	if err := db.Select("id", "gold").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	balances := map[string]int{CurrencyGold: user.Gold}
+
+	var codes []string
+	db.Model(&models.Currency{}).Where("is_active = ?", true).Pluck("code", &codes)
+	for _, code := range codes {
+		if _, ok := balances[code]; !ok {
+			balances[code] = 0
+		}
+	}
+
+	var wallets []models.UserWallet
+	db.Where("user_id = ?", userID).Find(&wallets)
+	for _, w := range wallets {
+		balances[w.Currency] = w.Balance
+	}
+	return balances, nil
+}
+
+// ChangeExp 变动用户经验并写入流水，同时重新计算等级
+func ChangeExp(tx *gorm.DB, userID uint, delta int, meta LogMeta) (*ExpResult, error) {
+	user, err := LockUser(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExpResult{Exp: user.Exp, Level: user.Level, PrevLevel: user.Level}
+	if delta == 0 {
+		return result, nil
+	}
+
+	newExp := user.Exp + delta
+	if newExp < 0 {
+		return result, ErrInsufficientExp
+	}
+	newLevel := CalculateLevel(newExp)
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"exp":   newExp,
+		"level": newLevel,
+	}).Error; err != nil {
+		return result, err
+	}
+	result.Exp, result.Level = newExp, newLevel
+
+	logType, amount := LogExpIn, delta
+	if delta < 0 {
+		logType, amount = LogExpOut, -delta
+	}
+	if _, err := writeLog(tx, user, CurrencyExp, logType, amount, newExp, meta); err != nil {
+		return result, err
+	}
+	realtime.PublishAfterCommit(tx, realtime.UserTopic(userID), realtime.Event{
+		Type: realtime.EventLevel,
+		Data: result,
+	})
+
+	// 升级通知
+	if newLevel > result.PrevLevel {
+		if err := FireWebhook(tx, userID, WebhookLevelUp, result); err != nil {
+			return result, err
+		}
+		return result, Notify(tx, &models.Notification{
+			UserID:  userID,
+			Type:    NotifyLevelUp,
+			Title:   fmt.Sprintf("升级到 Lv.%d", newLevel),
+			Body:    fmt.Sprintf("恭喜! 你已从 Lv.%d 升级到 Lv.%d", result.PrevLevel, newLevel),
+			Link:    "/profile",
+			RefType: RefTypeLevel,
+			RefID:   uint(newLevel),
+		})
+	}
+	return result, nil
+}
+
+// writeLog 写入流水记录, 流水归属用户所在空间
+func writeLog(tx *gorm.DB, user *models.SysUser, currency, logType string, amount, balance int, meta LogMeta) (*models.UserLog, error) {
+	log := &models.UserLog{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Currency:    currency,
+		Type:        logType,
+		Amount:      amount,
+		Balance:     balance,
+		Description: truncateRunes(meta.Description, 255), // 退款等拼接描述可能超出字段长度
+		RefType:     meta.RefType,
+		RefID:       meta.RefID,
+		OperatorID:  meta.OperatorID,
+	}
+	return log, tx.Create(log).Error
+}
+
+// CalculateLevel 根据经验计算等级
+func CalculateLevel(exp int) int {
+	// 等级公式: 每升一级需要的经验 = 等级 * 100
+	// Level 1: 0-99, Level 2: 100-299, Level 3: 300-599, ...
+	level := 1
+	requiredExp := 0
+	for {
+		requiredExp += level * 100
+		if exp < requiredExp {
+			return level
+		}
+		level++
+		if level > 100 { // 最高100级
+			return 100
+		}
+	}
+}
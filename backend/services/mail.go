@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// 邮件状态
+const (
+	MailPending = "pending"
+	MailSent    = "sent"
+	MailFailed  = "failed"
+)
+
+// mailBatchSize 每次处理的队列邮件数
+const mailBatchSize = 50
+
+// 邮件错误
+var (
+	ErrMailDisabled  = errors.New("邮件服务未配置")
+	ErrMailTemplate  = errors.New("邮件模板不存在")
+	ErrMailNoAddress = errors.New("用户未设置邮箱")
+	ErrMailAddress   = errors.New("邮箱格式不正确")
+	ErrMailNotFound  = errors.New("邮件不存在或无需重试")
+)
+
+// Mailer 邮件发送实现
+type Mailer interface {
+	Send(to, subject, htmlBody string) error
+}
+
+var (
+	mailerMu sync.RWMutex
+	mailer   Mailer
+)
+
+// SetMailer 设置邮件发送实现, 为nil时停用邮件
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	mailer = m
+}
+
+// currentMailer 当前的邮件发送实现
+func currentMailer() Mailer {
+	mailerMu.RLock()
+	defer mailerMu.RUnlock()
+	return mailer
+}
+
+// MailEnabled 是否已启用邮件
+func MailEnabled() bool {
+	return currentMailer() != nil
+}
+
+// InitMail 按配置启用SMTP邮件并注册邮件通知渠道, 未配置服务器时不启用
+func InitMail() {
+	cfg := config.AppConfig.Mail
+	if cfg.Host == "" {
+		return
+	}
+	SetMailer(&SMTPMailer{Config: cfg})
+	RegisterChannel(emailChannel{})
+}
+
+// SMTPMailer 通过SMTP发送邮件, 服务器支持时自动启用STARTTLS
+type SMTPMailer struct {
+	Config config.MailConfig
+}
+
+// Send 发送HTML邮件
+func (m *SMTPMailer) Send(to, subject, htmlBody string) error {
+	from, err := mail.ParseAddress(m.Config.From)
+	if err != nil {
+		return fmt.Errorf("发件人格式不正确: %w", err)
+	}
+	var auth smtp.Auth
+	if m.Config.Username != "" {
+		auth = smtp.PlainAuth("", m.Config.Username, m.Config.Password, m.Config.Host)
+	}
+	addr := net.JoinHostPort(m.Config.Host, m.Config.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{to}, buildMailMessage(from, to, subject, htmlBody))
+}
+
+// buildMailMessage 组装MIME邮件, 主题和正文按UTF-8编码
+func buildMailMessage(from *mail.Address, to, subject, htmlBody string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from.String() + "\r\n")
+	buf.WriteString("To: " + to + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(htmlBody))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// NormalizeEmail 校验邮箱地址, 只接受不带显示名的纯地址
+func NormalizeEmail(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || len(value) > 100 {
+		return "", ErrMailAddress
+	}
+	return value, nil
+}
+
+// EnqueueMail 按模板渲染邮件并加入发送队列, 与业务流程在同一事务中写入
+func EnqueueMail(tx *gorm.DB, user *models.SysUser, template string, data MailData) error {
+	if user.Email == "" {
+		return ErrMailNoAddress
+	}
+	if data == nil {
+		data = MailData{}
+	}
+	if _, ok := data["Name"]; !ok {
+		name := user.Nickname
+		if name == "" {
+			name = user.Username
+		}
+		data["Name"] = name
+	}
+	return enqueueMail(tx, user.TenantID, user.ID, user.Email, template, data)
+}
+
+// SendTestMail 向指定地址发送测试邮件, 用于检查邮件配置
+func SendTestMail(tx *gorm.DB, tenantID uint, to string) error {
+	return enqueueMail(tx, tenantID, 0, to, MailTemplateTest, MailData{})
+}
+
+// enqueueMail 渲染并写入发送队列
+func enqueueMail(tx *gorm.DB, tenantID, userID uint, to, template string, data MailData) error {
+	if !MailEnabled() {
+		return ErrMailDisabled
+	}
+	subject, body, err := renderMail(template, data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.EmailMessage{
+		TenantID:      tenantID,
+		UserID:        userID,
+		To:            to,
+		Template:      template,
+		Subject:       subject,
+		Body:          body,
+		Status:        MailPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// mailBackoff 第n次尝试失败后的重试间隔: 1、4、9、16... 分钟
+func mailBackoff(attempts int) time.Duration {
+	return time.Duration(attempts*attempts) * time.Minute
+}
+
+// ProcessMailQueue 发送到期的队列邮件, 失败时按退避间隔重试, 超过最大次数标记为失败
+func ProcessMailQueue(db *gorm.DB, now time.Time) error {
+	m := currentMailer()
+	if m == nil {
+		return nil
+	}
+
+	var messages []models.EmailMessage
+	if err := db.Where("status = ? AND next_attempt_at <= ?", MailPending, now).
+		Order("id").Limit(mailBatchSize).Find(&messages).Error; err != nil {
+		return err
+	}
+
+	for i := range messages {
+		msg := &messages[i]
+		// 先记录本次尝试并推迟下次尝试时间, 多实例同时处理时只有一个能占用
+		attempts := msg.Attempts + 1
+		result := db.Model(msg).Where("status = ? AND attempts = ?", MailPending, msg.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        attempts,
+				"next_attempt_at": now.Add(mailBackoff(attempts)),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("邮件 %d 更新失败: %w", msg.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		updates := map[string]interface{}{}
+		if err := m.Send(msg.To, msg.Subject, msg.Body); err != nil {
+			updates["last_error"] = truncateRunes(err.Error(), 500)
+			if attempts >= config.AppConfig.Mail.MaxAttempts {
+				updates["status"] = MailFailed
+			}
+		} else {
+			updates["status"] = MailSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+		}
+		// 一次性链接不在发送结束后继续留存
+		if _, done := updates["status"]; done && containsString(secretMailTemplates, msg.Template) {
+			updates["body"] = ""
+		}
+		if err := db.Model(msg).Updates(updates).Error; err != nil {
+			return fmt.Errorf("邮件 %d 更新失败: %w", msg.ID, err)
+		}
+	}
+	return nil
+}
+
+// RetryMail 将发送失败的邮件重新加入队列, 含一次性链接的邮件正文已清空, 需用户重新申请
+func RetryMail(db *gorm.DB, messageID uint) error {
+	result := db.Model(&models.EmailMessage{}).
+		Where("id = ? AND status = ? AND template NOT IN ?", messageID, MailFailed, secretMailTemplates).
+		Updates(map[string]interface{}{
+			"status":          MailPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMailNotFound
+	}
+	return nil
+}
+
+// emailChannel 邮件通知渠道
+type emailChannel struct{}
+
+// Name 渠道标识
+func (emailChannel) Name() string {
+	return "email"
+}
+
+// Send 将通知加入邮件队列
+func (emailChannel) Send(db *gorm.DB, user *models.SysUser, n *models.Notification) error {
+	template := MailTemplateNotification
+	if n.Type == NotifyReminder {
+		template = MailTemplateReminder
+	}
+	return EnqueueMail(db, user, template, MailData{"Title": n.Title, "Body": n.Body})
+}
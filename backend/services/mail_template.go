@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// 邮件模板
+const (
+	MailTemplateTest          = "test"
+	MailTemplatePasswordReset = "password_reset"
+	MailTemplateVerifyEmail   = "verify_email"
+	MailTemplateWeeklySummary = "weekly_summary"
+	MailTemplateReminder      = "reminder"
+	MailTemplateNotification  = "notification"
+)
+
+// secretMailTemplates 正文包含一次性链接的模板, 发送结束后清空正文且不允许重试
+var secretMailTemplates = []string{MailTemplatePasswordReset, MailTemplateVerifyEmail}
+
+// mailLayout 邮件公共布局, 各模板定义 content 块
+const mailLayout = `<!DOCTYPE html>
+<html><body style="font-family:sans-serif;color:#333;line-height:1.6">
+<p>{{if .Name}}{{.Name}}, 你好:{{else}}你好:{{end}}</p>
+{{template "content" .}}
+<p style="color:#999;font-size:12px">此邮件由 Life RPG 自动发送, 请勿直接回复。</p>
+</body></html>`
+
+// mailTemplateSources 各模板的主题和正文
+var mailTemplateSources = map[string][2]string{
+	MailTemplateTest: {
+		"Life RPG 测试邮件",
+		`<p>这是一封测试邮件, 收到说明邮件服务配置正确。</p>`,
+	},
+	MailTemplatePasswordReset: {
+		"Life RPG 重置密码",
+		`<p>我们收到了重置密码的请求, 请在 {{.Minutes}} 分钟内点击下面的链接设置新密码:</p>
+<p><a href="{{.URL}}">{{.URL}}</a></p>
+<p>如果不是你本人操作, 请忽略此邮件, 密码不会被修改。</p>`,
+	},
+	MailTemplateVerifyEmail: {
+		"Life RPG 验证邮箱",
+		`<p>你正在将此邮箱绑定到 Life RPG 账号, 请在 {{.Minutes}} 分钟内点击下面的链接完成验证:</p>
+<p><a href="{{.URL}}">{{.URL}}</a></p>
+<p>如果不是你本人操作, 请忽略此邮件。</p>`,
+	},
+	MailTemplateWeeklySummary: {
+		"Life RPG 周报 ({{.Start}} ~ {{.End}})",
+		`<p>过去一周的冒险记录:</p>
+<ul>
+<li>完成任务: {{.Tasks}} 次</li>
+<li>获得经验: {{.Exp}}</li>
+<li>获得金币: {{.Gold}}</li>
+<li>当前等级: Lv.{{.Level}}</li>
+</ul>
+<p>{{if .Tasks}}继续保持, 新的一周也要加油!{{else}}这周还没有完成任务, 从一个小目标开始吧!{{end}}</p>`,
+	},
+	MailTemplateReminder: {
+		"{{.Title}}",
+		`<p>{{.Body}}</p>`,
+	},
+	MailTemplateNotification: {
+		"{{.Title}}",
+		`<p>{{.Body}}</p>`,
+	},
+}
+
+// mailTemplate 解析后的邮件模板
+type mailTemplate struct {
+	subject *template.Template
+	body    *htmltemplate.Template
+}
+
+// mailTemplates 启动时解析全部模板, 模板有误时直接panic
+var mailTemplates = func() map[string]mailTemplate {
+	result := map[string]mailTemplate{}
+	for name, src := range mailTemplateSources {
+		body := htmltemplate.Must(htmltemplate.New(name).Parse(mailLayout))
+		result[name] = mailTemplate{
+			subject: template.Must(template.New(name).Parse(src[0])),
+			body:    htmltemplate.Must(body.New("content").Parse(src[1])),
+		}
+	}
+	return result
+}()
+
+// MailData 邮件模板数据
+type MailData map[string]interface{}
+
+// renderMail 渲染邮件主题和正文
+func renderMail(name string, data MailData) (string, string, error) {
+	tpl, ok := mailTemplates[name]
+	if !ok {
+		return "", "", ErrMailTemplate
+	}
+	var subject, body bytes.Buffer
+	if err := tpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tpl.body.ExecuteTemplate(&body, name, data); err != nil {
+		return "", "", err
+	}
+	// 主题不允许换行, 避免注入邮件头
+	return strings.Join(strings.Fields(subject.String()), " "), body.String(), nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMailBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 4 * time.Minute},
+		{3, 9 * time.Minute},
+		{5, 25 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := mailBackoff(tt.attempts); got != tt.want {
+			t.Errorf("mailBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"life-rpg/models"
+	"life-rpg/realtime"
+
+	"gorm.io/gorm"
+)
+
+// 通知类型
+const (
+	NotifyPartnerInvite = "partner_invite"
+	NotifyPartnerMissed = "partner_missed"
+	NotifyNudge         = "nudge"
+	NotifyLevelUp       = "level_up"
+	NotifyTaskReview    = "task_review"
+	NotifyGift          = "gift"
+	NotifyTransfer      = "transfer"
+	NotifyAdmin         = "admin"
+)
+
+// RefTypeLevel 升级通知的关联类型, RefID 为达到的等级
+const RefTypeLevel = "level"
+
+// ErrNotificationNotFound 通知不存在
+var ErrNotificationNotFound = errors.New("通知不存在")
+
+// Notify 发送站内通知, 与业务流程在同一事务中写入, 提交后实时推送
+func Notify(tx *gorm.DB, n *models.Notification) error {
+	if err := tx.Create(n).Error; err != nil {
+		return err
+	}
+	realtime.PublishAfterCommit(tx, realtime.UserTopic(n.UserID), realtime.Event{
+		Type: realtime.EventNotification,
+		Data: n,
+	})
+	return nil
+}
+
+// UnreadCount 用户未读通知数
+func UnreadCount(db *gorm.DB, userID uint) int64 {
+	var count int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count)
+	return count
+}
+
+// MarkRead 将用户的一条通知标为已读, 已读的通知保持原已读时间
+func MarkRead(db *gorm.DB, userID, notificationID uint) error {
+	var n models.Notification
+	if err := db.Where("user_id = ?", userID).First(&n, notificationID).Error; err != nil {
+		return ErrNotificationNotFound
+	}
+	if n.ReadAt != nil {
+		return nil
+	}
+	return db.Model(&n).Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead 将用户全部未读通知标为已读, 返回标记的条数
+func MarkAllRead(db *gorm.DB, userID uint) (int64, error) {
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// SendAdminMessage 管理员向指定用户发送消息, userIDs 为空时发送给全部正常状态的用户
+func SendAdminMessage(tx *gorm.DB, senderID uint, userIDs []uint, title, body, link string) (int, error) {
+	query := tx.Model(&models.SysUser{}).Where("status = ?", 1)
+	if len(userIDs) > 0 {
+		query = query.Where("id IN ?", userIDs)
+	}
+	var targets []uint
+	if err := query.Pluck("id", &targets).Error; err != nil {
+		return 0, err
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	notifications := make([]models.Notification, 0, len(targets))
+	for _, id := range targets {
+		notifications = append(notifications, models.Notification{
+			UserID:   id,
+			Type:     NotifyAdmin,
+			Title:    title,
+			Body:     body,
+			Link:     link,
+			SenderID: senderID,
+		})
+	}
+	if err := tx.CreateInBatches(notifications, 200).Error; err != nil {
+		return 0, err
+	}
+	for i := range notifications {
+		realtime.PublishAfterCommit(tx, realtime.UserTopic(notifications[i].UserID), realtime.Event{
+			Type: realtime.EventNotification,
+			Data: &notifications[i],
+		})
+	}
+	return len(notifications), nil
+}
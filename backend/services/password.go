@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// passwordResetTTL 重置密码链接有效期
+const passwordResetTTL = 30 * time.Minute
+
+// passwordResetInterval 同一用户两次申请重置的最短间隔
+const passwordResetInterval = time.Minute
+
+// ErrResetToken 重置令牌无效
+var ErrResetToken = errors.New("重置链接无效或已过期")
+
+// hashToken 令牌只保存哈希, 数据库泄露时无法直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset 生成重置令牌并发送重置密码邮件, 短时间内重复申请时不再发送
+func RequestPasswordReset(tx *gorm.DB, user *models.SysUser) error {
+	if user.Email == "" {
+		return ErrMailNoAddress
+	}
+
+	var count int64
+	tx.Model(&models.PasswordReset{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-passwordResetInterval)).
+		Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}).Error; err != nil {
+		return err
+	}
+
+	return EnqueueMail(tx, user, MailTemplatePasswordReset, MailData{
+		"URL":     config.AppConfig.Mail.ResetURL + "?token=" + token,
+		"Minutes": int(passwordResetTTL / time.Minute),
+	})
+}
+
+// ResetPassword 校验令牌并设置新密码 (已加密), 同时作废该用户其他未使用的令牌
+func ResetPassword(tx *gorm.DB, token, hashedPassword string) error {
+	var reset models.PasswordReset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).
+		First(&reset).Error; err != nil {
+		return ErrResetToken
+	}
+
+	result := tx.Model(&models.SysUser{}).Where("id = ? AND status = 1", reset.UserID).
+		Update("password", hashedPassword)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrResetToken
+	}
+
+	return tx.Model(&models.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", reset.UserID).
+		Update("used_at", time.Now()).Error
+}
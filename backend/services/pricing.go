@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 优惠券类型
+const (
+	CouponPercent = "percent"
+	CouponAmount  = "amount"
+)
+
+// 优惠券错误
+var (
+	ErrCouponInvalid  = errors.New("优惠券无效或已过期")
+	ErrCouponUsedUp   = errors.New("优惠券已被领完")
+	ErrCouponUserUsed = errors.New("已达到该优惠券的使用次数")
+	ErrCouponMinCost  = errors.New("未达到优惠券使用门槛")
+	ErrCouponCategory = errors.New("该优惠券不适用于此奖励")
+)
+
+// PriceBreakdown 价格明细
+type PriceBreakdown struct {
+	Original       int    `json:"original"`
+	SaleID         uint   `json:"saleId,omitempty"`
+	SaleName       string `json:"saleName,omitempty"`
+	SaleDiscount   int    `json:"saleDiscount"`
+	CouponCode     string `json:"couponCode,omitempty"`
+	CouponDiscount int    `json:"couponDiscount"`
+	Final          int    `json:"final"`
+}
+
+// Describe 价格明细的文字描述, 写入流水说明
+func (b *PriceBreakdown) Describe() string {
+	if b.SaleDiscount == 0 && b.CouponDiscount == 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("原价%d", b.Original)}
+	if b.SaleDiscount > 0 {
+		parts = append(parts, fmt.Sprintf("%s-%d", b.SaleName, b.SaleDiscount))
+	}
+	if b.CouponDiscount > 0 {
+		parts = append(parts, fmt.Sprintf("优惠券%s-%d", b.CouponCode, b.CouponDiscount))
+	}
+	parts = append(parts, fmt.Sprintf("实付%d", b.Final))
+	return strings.Join(parts, ", ")
+}
+
+// ActiveSales 当前生效的促销活动
+func ActiveSales(db *gorm.DB) []models.SaleEvent {
+	now := time.Now()
+	var sales []models.SaleEvent
+	db.Where("is_active = ? AND start_at <= ? AND end_at > ?", true, now, now).Find(&sales)
+	return sales
+}
+
+// BestSale 从促销活动中选出对奖励折扣最大的一个
+func BestSale(sales []models.SaleEvent, reward *models.Reward) *models.SaleEvent {
+	var best *models.SaleEvent
+	for i := range sales {
+		sale := &sales[i]
+		if sale.RewardID != 0 && sale.RewardID != reward.ID {
+			continue
+		}
+		if sale.RewardID == 0 && sale.Category != "" && sale.Category != reward.Category {
+			continue
+		}
+		if best == nil || sale.Percent > best.Percent {
+			best = sale
+		}
+	}
+	return best
+}
+
+// QuotePrice 计算奖励的最终价格: 先应用促销, 再应用优惠券
+func QuotePrice(db *gorm.DB, userID uint, reward *models.Reward, couponCode string) (*PriceBreakdown, *models.Coupon, error) {
+	b := &PriceBreakdown{Original: reward.Cost, Final: reward.Cost}
+
+	if sale := BestSale(ActiveSales(db), reward); sale != nil {
+		b.SaleID, b.SaleName = sale.ID, sale.Name
+		b.SaleDiscount = reward.Cost * sale.Percent / 100
+		b.Final -= b.SaleDiscount
+	}
+
+	if couponCode == "" {
+		return b, nil, nil
+	}
+	var coupon models.Coupon
+	if err := db.Where("code = ?", couponCode).First(&coupon).Error; err != nil {
+		return b, nil, ErrCouponInvalid
+	}
+	if err := checkCoupon(db, &coupon, userID, reward, b.Final); err != nil {
+		return b, nil, err
+	}
+
+	b.CouponCode = coupon.Code
+	if coupon.Type == CouponAmount {
+		b.CouponDiscount = coupon.Value
+	} else {
+		b.CouponDiscount = b.Final * coupon.Value / 100
+	}
+	if b.CouponDiscount > b.Final {
+		b.CouponDiscount = b.Final
+	}
+	b.Final -= b.CouponDiscount
+	return b, &coupon, nil
+}
+
+// checkCoupon 校验优惠券对该用户和奖励是否可用
+func checkCoupon(db *gorm.DB, coupon *models.Coupon, userID uint, reward *models.Reward, price int) error {
+	now := time.Now()
+	if !coupon.IsActive || (coupon.StartAt != nil && now.Before(*coupon.StartAt)) ||
+		(coupon.EndAt != nil && !now.Before(*coupon.EndAt)) {
+		return ErrCouponInvalid
+	}
+	if coupon.MaxUses > 0 && coupon.UsedCount >= coupon.MaxUses {
+		return ErrCouponUsedUp
+	}
+	if coupon.Category != "" && coupon.Category != reward.Category {
+		return ErrCouponCategory
+	}
+	if price < coupon.MinCost {
+		return ErrCouponMinCost
+	}
+	if coupon.PerUserLimit > 0 {
+		var used int64
+		db.Model(&models.CouponRedemption{}).
+			Where("coupon_id = ? AND user_id = ?", coupon.ID, userID).
+			Count(&used)
+		if int(used) >= coupon.PerUserLimit {
+			return ErrCouponUserUsed
+		}
+	}
+	return nil
+}
+
+// RedeemCoupon 在事务内锁定优惠券、复核可用性并记录使用
+// 需在扣款后调用, logID 为本次兑换的扣款流水, 实付为0时没有流水传0
+func RedeemCoupon(tx *gorm.DB, couponID, userID uint, reward *models.Reward, price, discount int, logID uint) error {
+	var coupon models.Coupon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, couponID).Error; err != nil {
+		return ErrCouponInvalid
+	}
+	if err := checkCoupon(tx, &coupon, userID, reward, price); err != nil {
+		return err
+	}
+	if err := tx.Model(&coupon).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.CouponRedemption{
+		CouponID: coupon.ID,
+		UserID:   userID,
+		RewardID: reward.ID,
+		LogID:    logID,
+		Discount: discount,
+	}).Error
+}
+
+// RevokeCoupon 退款时撤销兑换流水对应的优惠券使用记录并恢复使用次数
+func RevokeCoupon(tx *gorm.DB, logID uint) error {
+	var redemption models.CouponRedemption
+	if err := tx.Where("log_id = ?", logID).First(&redemption).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := tx.Model(&models.Coupon{}).Where("id = ? AND used_count > 0", redemption.CouponID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&redemption).Error
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// RefTypeReconcile 对账修正流水的关联类型
+const RefTypeReconcile = "reconcile"
+
+// Drift 余额与流水的偏差
+type Drift struct {
+	Field    string `json:"field"`    // 币种代码或exp
+	Balance  int    `json:"balance"`  // 用户当前余额
+	Replayed int    `json:"replayed"` // 流水重放得到的余额
+	Diff     int    `json:"diff"`     // Balance - Replayed
+}
+
+// BrokenEntry 余额快照与上一条流水不连续的记录
+// 之后存在对账修正流水的断点标记为已修正, 仍保留在报告中供追溯
+type BrokenEntry struct {
+	Log      models.UserLog `json:"log"`
+	Expected int            `json:"expected"` // 按上一条流水推算的余额
+	Resolved bool           `json:"resolved"` // 已被之后的修正流水补记
+}
+
+// ReconcileReport 单个用户的对账结果
+type ReconcileReport struct {
+	UserID   uint          `json:"userId"`
+	Username string        `json:"username"`
+	Drifts   []Drift       `json:"drifts"`
+	Broken   []BrokenEntry `json:"broken"`
+	Fixed    bool          `json:"fixed"`
+}
+
+// Clean 是否账实相符, 已修正的断点不影响结果
+func (r *ReconcileReport) Clean() bool {
+	if len(r.Drifts) > 0 {
+		return false
+	}
+	for _, b := range r.Broken {
+		if !b.Resolved {
+			return false
+		}
+	}
+	return true
+}
+
+// replayField 按ID顺序重放单个币种的流水, 返回流水合计与不连续的记录
+// 修正流水本身不做连续性校验, 并将此前的断点标记为已修正
+func replayField(logs []models.UserLog, field string) (int, []BrokenEntry) {
+	replayed, last := 0, 0
+	var broken []BrokenEntry
+	for _, l := range logs {
+		var signed int
+		switch l.Type {
+		case LogTypeIn(field):
+			signed = l.Amount
+		case LogTypeOut(field):
+			signed = -l.Amount
+		default:
+			continue
+		}
+		replayed += signed
+		if l.RefType == RefTypeReconcile {
+			for i := range broken {
+				broken[i].Resolved = true
+			}
+		} else if l.Balance != last+signed {
+			broken = append(broken, BrokenEntry{Log: l, Expected: last + signed})
+		}
+		last = l.Balance
+	}
+	return replayed, broken
+}
+
+// ReconcileUser 重放用户流水并与账户余额比对
+// fix 为 true 时为每个偏差写入一条修正流水，使流水合计与当前余额一致
+// (账户余额保持不变，缺失的历史变动以修正流水补记)
+func ReconcileUser(db *gorm.DB, userID uint, fix bool) (*ReconcileReport, error) {
+	var report *ReconcileReport
+	err := db.Transaction(func(tx *gorm.DB) error {
+		user, err := LockUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		report = &ReconcileReport{UserID: user.ID, Username: user.Username}
+
+		var logs []models.UserLog
+		if err := tx.Where("user_id = ?", userID).Order("id").Find(&logs).Error; err != nil {
+			return err
+		}
+
+		// 参与对账的余额: 经验 + 全部币种
+		balances, err := Balances(tx, userID)
+		if err != nil {
+			return err
+		}
+		balances[CurrencyExp] = user.Exp
+		fields := make([]string, 0, len(balances))
+		for field := range balances {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			replayed, broken := replayField(logs, field)
+			report.Broken = append(report.Broken, broken...)
+			if diff := balances[field] - replayed; diff != 0 {
+				report.Drifts = append(report.Drifts, Drift{
+					Field:    field,
+					Balance:  balances[field],
+					Replayed: replayed,
+					Diff:     diff,
+				})
+			}
+		}
+
+		if !fix || len(report.Drifts) == 0 {
+			return nil
+		}
+		for _, d := range report.Drifts {
+			logType, amount := LogTypeIn(d.Field), d.Diff
+			if d.Diff < 0 {
+				logType, amount = LogTypeOut(d.Field), -d.Diff
+			}
+			if _, err := writeLog(tx, user, d.Field, logType, amount, d.Balance, LogMeta{
+				Description: fmt.Sprintf("对账修正: 流水合计%d, 账户余额%d", d.Replayed, d.Balance),
+				RefType:     RefTypeReconcile,
+			}); err != nil {
+				return err
+			}
+		}
+		report.Fixed = true
+		return nil
+	})
+	return report, err
+}
+
+// ReconcileAll 对所有用户执行对账，仅返回存在问题的用户
+func ReconcileAll(db *gorm.DB, fix bool) ([]*ReconcileReport, error) {
+	var userIDs []uint
+	if err := db.Model(&models.SysUser{}).Order("id").Pluck("id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+
+	reports := []*ReconcileReport{}
+	for _, id := range userIDs {
+		report, err := ReconcileUser(db, id, fix)
+		if err != nil {
+			return reports, err
+		}
+		if !report.Clean() {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+
+	"life-rpg/models"
+)
+
+func TestReplayField(t *testing.T) {
+	in, out := LogTypeIn(CurrencyGold), LogTypeOut(CurrencyGold)
+	tests := []struct {
+		name         string
+		logs         []models.UserLog
+		wantReplayed int
+		wantBroken   []uint // 断点流水ID
+		wantResolved []bool
+	}{
+		{
+			name: "连续流水",
+			logs: []models.UserLog{
+				{ID: 1, Type: in, Amount: 100, Balance: 100},
+				{ID: 2, Type: out, Amount: 30, Balance: 70},
+			},
+			wantReplayed: 70,
+		},
+		{
+			name: "忽略其他币种",
+			logs: []models.UserLog{
+				{ID: 1, Type: in, Amount: 100, Balance: 100},
+				{ID: 2, Type: LogTypeIn("gem"), Amount: 5, Balance: 5},
+				{ID: 3, Type: out, Amount: 30, Balance: 70},
+			},
+			wantReplayed: 70,
+		},
+		{
+			name: "缺失流水产生断点",
+			logs: []models.UserLog{
+				{ID: 1, Type: in, Amount: 100, Balance: 100},
+				{ID: 2, Type: in, Amount: 10, Balance: 150},
+			},
+			wantReplayed: 110,
+			wantBroken:   []uint{2},
+			wantResolved: []bool{false},
+		},
+		{
+			name: "修正流水保留此前断点并标记已修正",
+			logs: []models.UserLog{
+				{ID: 1, Type: in, Amount: 100, Balance: 100},
+				{ID: 2, Type: in, Amount: 10, Balance: 150},
+				{ID: 3, Type: in, Amount: 40, Balance: 150, RefType: RefTypeReconcile},
+			},
+			wantReplayed: 150,
+			wantBroken:   []uint{2},
+			wantResolved: []bool{true},
+		},
+		{
+			name: "修正后的新断点未修正",
+			logs: []models.UserLog{
+				{ID: 1, Type: in, Amount: 100, Balance: 100},
+				{ID: 2, Type: in, Amount: 10, Balance: 150},
+				{ID: 3, Type: in, Amount: 40, Balance: 150, RefType: RefTypeReconcile},
+				{ID: 4, Type: out, Amount: 50, Balance: 90},
+			},
+			wantReplayed: 100,
+			wantBroken:   []uint{2, 4},
+			wantResolved: []bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayed, broken := replayField(tt.logs, CurrencyGold)
+			if replayed != tt.wantReplayed {
+				t.Errorf("replayed = %d, want %d", replayed, tt.wantReplayed)
+			}
+			if len(broken) != len(tt.wantBroken) {
+				t.Fatalf("broken = %d 条, want %d 条", len(broken), len(tt.wantBroken))
+			}
+			for i, b := range broken {
+				if b.Log.ID != tt.wantBroken[i] || b.Resolved != tt.wantResolved[i] {
+					t.Errorf("broken[%d] = {ID:%d Resolved:%v}, want {ID:%d Resolved:%v}",
+						i, b.Log.ID, b.Resolved, tt.wantBroken[i], tt.wantResolved[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileReportClean(t *testing.T) {
+	tests := []struct {
+		name   string
+		report ReconcileReport
+		want   bool
+	}{
+		{"无问题", ReconcileReport{}, true},
+		{"存在偏差", ReconcileReport{Drifts: []Drift{{Field: CurrencyGold, Diff: 10}}}, false},
+		{"存在未修正断点", ReconcileReport{Broken: []BrokenEntry{{Resolved: false}}}, false},
+		{"断点均已修正", ReconcileReport{Broken: []BrokenEntry{{Resolved: true}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.report.Clean(); got != tt.want {
+				t.Errorf("Clean() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 容器镜像可能不含时区数据
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// NotifyReminder 任务提醒通知类型
+const NotifyReminder = "reminder"
+
+// 提醒错误
+var (
+	ErrReminderTime     = errors.New("提醒时间格式应为HH:MM")
+	ErrReminderTimezone = errors.New("时区无效")
+	ErrReminderWeekdays = errors.New("星期应为1-7")
+	ErrReminderChannel  = errors.New("不支持的通知渠道")
+	ErrReminderLimit    = errors.New("提醒数量已达上限")
+	ErrReminderNotFound = errors.New("提醒不存在")
+)
+
+var locationCache sync.Map
+
+// loadLocation 加载并缓存时区
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
+
+// NormalizeReminder 校验提醒设置, 补全默认时区并规范星期和渠道的写法
+func NormalizeReminder(r *models.TaskReminder) error {
+	if _, err := time.Parse("15:04", r.RemindAt); err != nil || len(r.RemindAt) != 5 {
+		return ErrReminderTime
+	}
+	if r.Timezone == "" {
+		r.Timezone = config.AppConfig.Reminder.DefaultTimezone
+	}
+	if _, err := loadLocation(r.Timezone); err != nil {
+		return ErrReminderTimezone
+	}
+
+	days, err := parseWeekdays(r.Weekdays)
+	if err != nil {
+		return err
+	}
+	parts := make([]string, 0, len(days))
+	for _, d := range days {
+		parts = append(parts, strconv.Itoa(d))
+	}
+	r.Weekdays = strings.Join(parts, ",")
+
+	names, ok := ParseChannels(r.Channels)
+	if !ok {
+		return ErrReminderChannel
+	}
+	r.Channels = strings.Join(names, ",")
+	return nil
+}
+
+// CheckReminderLimit 校验用户的提醒数量
+func CheckReminderLimit(db *gorm.DB, userID uint) error {
+	var count int64
+	db.Model(&models.TaskReminder{}).Where("user_id = ?", userID).Count(&count)
+	if limit := config.AppConfig.Reminder.MaxPerUser; limit > 0 && int(count) >= limit {
+		return ErrReminderLimit
+	}
+	return nil
+}
+
+// parseWeekdays 解析逗号分隔的星期, 1为周一、7为周日
+func parseWeekdays(value string) ([]int, error) {
+	seen := map[int]bool{}
+	var days []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := strconv.Atoi(part)
+		if err != nil || d < 1 || d > 7 {
+			return nil, ErrReminderWeekdays
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	sort.Ints(days)
+	return days, nil
+}
+
+// reminderDue 按提醒的时区判断当前是否已到提醒时间, 返回当地日期和当地当天零点
+func reminderDue(r *models.TaskReminder, now time.Time) (string, time.Time, bool) {
+	loc, err := loadLocation(r.Timezone)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	local := now.In(loc)
+	date := local.Format("2006-01-02")
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if r.LastSentDate == date || local.Format("15:04") < r.RemindAt {
+		return date, dayStart, false
+	}
+
+	if days, _ := parseWeekdays(r.Weekdays); len(days) > 0 {
+		weekday := int(local.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		matched := false
+		for _, d := range days {
+			matched = matched || d == weekday
+		}
+		if !matched {
+			return date, dayStart, false
+		}
+	}
+	return date, dayStart, true
+}
+
+// reminderTaskDone 任务是否已完成 (含待审核), 每日任务按提醒时区的当天计算
+func reminderTaskDone(db *gorm.DB, r *models.TaskReminder, dayStart time.Time) bool {
+	query := db.Model(&models.UserTask{}).
+		Where("user_id = ? AND task_id = ? AND status <> ?", r.UserID, r.TaskID, TaskRejected)
+	if r.Task.Type == "daily" {
+		query = query.Where("completed_at >= ?", dayStart)
+	}
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+// DispatchReminders 检查到期的任务提醒, 任务未完成时发送站内通知并通过所选渠道推送
+func DispatchReminders(db *gorm.DB, now time.Time) error {
+	var reminders []models.TaskReminder
+	if err := db.Preload("Task").Where("is_active = ?", true).Find(&reminders).Error; err != nil {
+		return err
+	}
+
+	for i := range reminders {
+		r := &reminders[i]
+		if r.Task == nil || !r.Task.IsActive {
+			continue
+		}
+		date, dayStart, due := reminderDue(r, now)
+		if !due {
+			continue
+		}
+
+		// 先占用当天的提醒, 避免重复执行时重复发送
+		result := db.Model(r).Where("last_sent_date <> ? OR last_sent_date IS NULL", date).
+			Update("last_sent_date", date)
+		if result.Error != nil {
+			return fmt.Errorf("提醒 %d 更新失败: %w", r.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if reminderTaskDone(db, r, dayStart) {
+			// 一次性任务已完成, 之后无需再提醒
+			if r.Task.Type == "once" {
+				db.Model(r).Update("is_active", false)
+			}
+			continue
+		}
+		if err := sendReminder(db, r); err != nil {
+			return fmt.Errorf("提醒 %d 发送失败: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendReminder 发送任务提醒
+func sendReminder(db *gorm.DB, r *models.TaskReminder) error {
+	var user models.SysUser
+	if err := db.First(&user, r.UserID).Error; err != nil || user.Status != 1 {
+		return nil
+	}
+
+	n := &models.Notification{
+		UserID:  r.UserID,
+		Type:    NotifyReminder,
+		Title:   "任务提醒: " + r.Task.Title,
+		Body:    fmt.Sprintf("「%s」还没有完成, 记得抽空完成哦", r.Task.Title),
+		Link:    "/tasks",
+		RefType: RefTypeTask,
+		RefID:   r.TaskID,
+	}
+	if err := Notify(db, n); err != nil {
+		return err
+	}
+	names, _ := ParseChannels(r.Channels)
+	DeliverChannels(db, &user, n, names)
+	return nil
+}
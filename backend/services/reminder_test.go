@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"life-rpg/models"
+)
+
+func TestReminderDue(t *testing.T) {
+	// 2024-01-01 为星期一
+	monday := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		reminder models.TaskReminder
+		now      time.Time
+		wantDate string
+		wantDue  bool
+	}{
+		{"未到提醒时间", models.TaskReminder{RemindAt: "08:01", Timezone: "UTC"}, monday, "2024-01-01", false},
+		{"恰好到提醒时间", models.TaskReminder{RemindAt: "08:00", Timezone: "UTC"}, monday, "2024-01-01", true},
+		{"当天已提醒", models.TaskReminder{RemindAt: "07:00", Timezone: "UTC", LastSentDate: "2024-01-01"}, monday, "2024-01-01", false},
+		{"前一天提醒过", models.TaskReminder{RemindAt: "07:00", Timezone: "UTC", LastSentDate: "2023-12-31"}, monday, "2024-01-01", true},
+		{"星期匹配", models.TaskReminder{RemindAt: "07:00", Timezone: "UTC", Weekdays: "1,3,5"}, monday, "2024-01-01", true},
+		{"星期不匹配", models.TaskReminder{RemindAt: "07:00", Timezone: "UTC", Weekdays: "2,4"}, monday, "2024-01-01", false},
+		{"星期日为7", models.TaskReminder{RemindAt: "07:00", Timezone: "UTC", Weekdays: "7"}, monday.AddDate(0, 0, -1), "2023-12-31", true},
+		{"按提醒时区计算日期", models.TaskReminder{RemindAt: "07:00", Timezone: "Asia/Shanghai"},
+			time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC), "2024-01-02", true},
+		{"按提醒时区未到时间", models.TaskReminder{RemindAt: "09:00", Timezone: "Asia/Shanghai"},
+			time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC), "2024-01-02", false},
+		{"时区无效", models.TaskReminder{RemindAt: "07:00", Timezone: "Mars/Base"}, monday, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, dayStart, due := reminderDue(&tt.reminder, tt.now)
+			if date != tt.wantDate || due != tt.wantDue {
+				t.Errorf("reminderDue() = (%q, %v), want (%q, %v)", date, due, tt.wantDate, tt.wantDue)
+			}
+			if date != "" && dayStart.Format("2006-01-02 15:04") != date+" 00:00" {
+				t.Errorf("dayStart = %v, want %s 当地零点", dayStart, date)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefTypeSeason 赛季流水的关联类型
+const RefTypeSeason = "season"
+
+// 赛季奖励轨道
+const (
+	TrackFree    = "free"
+	TrackPremium = "premium"
+)
+
+// 赛季错误
+var (
+	ErrNoActiveSeason     = errors.New("当前没有进行中的赛季")
+	ErrSeasonArchived     = errors.New("赛季已结束")
+	ErrSeasonPremiumOwned = errors.New("已解锁高级通行证")
+	ErrSeasonPremiumOnly  = errors.New("该档位需要高级通行证")
+	ErrSeasonTierLocked   = errors.New("赛季积分不足, 档位未解锁")
+	ErrSeasonTierClaimed  = errors.New("该档位奖励已领取")
+	ErrSeasonOverlap      = errors.New("赛季时间与已有赛季重叠")
+)
+
+// ActiveSeason 当前进行中的赛季
+func ActiveSeason(db *gorm.DB) (*models.Season, error) {
+	var season models.Season
+	now := time.Now()
+	if err := db.Where("start_at <= ? AND end_at > ? AND archived_at IS NULL", now, now).
+		Order("start_at desc").First(&season).Error; err != nil {
+		return nil, ErrNoActiveSeason
+	}
+	return &season, nil
+}
+
+// CheckSeasonOverlap 校验赛季时间不与其他赛季重叠, excludeID 为更新时排除的自身ID
+func CheckSeasonOverlap(db *gorm.DB, startAt, endAt time.Time, excludeID uint) error {
+	var count int64
+	db.Model(&models.Season{}).
+		Where("id <> ? AND start_at < ? AND end_at > ?", excludeID, endAt, startAt).
+		Count(&count)
+	if count > 0 {
+		return ErrSeasonOverlap
+	}
+	return nil
+}
+
+// lockUserSeason 以行锁读取用户赛季进度, 不存在时创建
+func lockUserSeason(tx *gorm.DB, userID, seasonID uint) (*models.UserSeason, error) {
+	var progress models.UserSeason
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND season_id = ?", userID, seasonID).
+		FirstOrCreate(&progress, models.UserSeason{UserID: userID, SeasonID: seasonID}).Error
+	return &progress, err
+}
+
+// AddSeasonPoints 完成任务时按当前赛季比例累加赛季积分, 没有进行中的赛季时返回0
+func AddSeasonPoints(tx *gorm.DB, userID uint, exp int) (int, error) {
+	season, err := ActiveSeason(tx)
+	if err != nil {
+		return 0, nil
+	}
+	points := exp * season.PointPercent / 100
+	if points <= 0 {
+		return 0, nil
+	}
+	progress, err := lockUserSeason(tx, userID, season.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(progress).Update("points", progress.Points+points).Error; err != nil {
+		return 0, err
+	}
+	return points, nil
+}
+
+// UnlockPremium 购买当前赛季高级通行证
+func UnlockPremium(tx *gorm.DB, userID uint) (*models.UserSeason, error) {
+	season, err := ActiveSeason(tx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := lockUserSeason(tx, userID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Premium {
+		return nil, ErrSeasonPremiumOwned
+	}
+
+	if _, err := ChangeCurrency(tx, userID, season.PremiumCurrency, -season.PremiumPrice, LogMeta{
+		Description: fmt.Sprintf("解锁高级通行证: %s", season.Name),
+		RefType:     RefTypeSeason,
+		RefID:       season.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(progress).Updates(map[string]interface{}{
+		"premium":    true,
+		"premium_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	progress.Premium, progress.PremiumAt = true, &now
+	return progress, nil
+}
+
+// ClaimTier 领取赛季档位奖励, 赛季归档后不可再领取
+func ClaimTier(tx *gorm.DB, userID, tierID uint) (*models.SeasonTier, error) {
+	var tier models.SeasonTier
+	if err := tx.First(&tier, tierID).Error; err != nil {
+		return nil, err
+	}
+	var season models.Season
+	if err := tx.First(&season, tier.SeasonID).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if season.ArchivedAt != nil || now.Before(season.StartAt) || !now.Before(season.EndAt) {
+		return nil, ErrSeasonArchived
+	}
+
+	progress, err := lockUserSeason(tx, userID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Points < tier.Points {
+		return nil, ErrSeasonTierLocked
+	}
+	if tier.Track == TrackPremium && !progress.Premium {
+		return nil, ErrSeasonPremiumOnly
+	}
+
+	var count int64
+	tx.Model(&models.SeasonClaim{}).Where("user_id = ? AND tier_id = ?", userID, tier.ID).Count(&count)
+	if count > 0 {
+		return nil, ErrSeasonTierClaimed
+	}
+	claim := models.SeasonClaim{UserID: userID, TierID: tier.ID, SeasonID: season.ID}
+	if err := tx.Create(&claim).Error; err != nil {
+		return nil, ErrSeasonTierClaimed
+	}
+
+	track := "免费"
+	if tier.Track == TrackPremium {
+		track = "高级"
+	}
+	return &tier, GrantBundle(tx, userID, &tier.RewardBundle, LogMeta{
+		Description: fmt.Sprintf("%s %s奖励 Lv.%d", season.Name, track, tier.Level),
+		RefType:     RefTypeSeason,
+		RefID:       claim.ID,
+	})
+}
+
+// ReachedTier 按积分计算达到的档位等级
+func ReachedTier(tiers []models.SeasonTier, points int) int {
+	level := 0
+	for _, tier := range tiers {
+		if points >= tier.Points && tier.Level > level {
+			level = tier.Level
+		}
+	}
+	return level
+}
+
+// ArchiveEndedSeasons 归档已结束的赛季: 冻结用户进度并记录最终档位
+func ArchiveEndedSeasons(db *gorm.DB) error {
+	var seasons []models.Season
+	if err := db.Where("end_at <= ? AND archived_at IS NULL", time.Now()).
+		Preload("Tiers").Find(&seasons).Error; err != nil {
+		return err
+	}
+
+	for _, season := range seasons {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var progresses []models.UserSeason
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("season_id = ? AND archived = ?", season.ID, false).
+				Find(&progresses).Error; err != nil {
+				return err
+			}
+			for _, progress := range progresses {
+				if err := tx.Model(&progress).Updates(map[string]interface{}{
+					"archived":   true,
+					"final_tier": ReachedTier(season.Tiers, progress.Points),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&season).Update("archived_at", time.Now()).Error
+		})
+		if err != nil {
+			return fmt.Errorf("赛季 %d 归档失败: %w", season.ID, err)
+		}
+	}
+	return nil
+}
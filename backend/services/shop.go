@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrOutOfStock 库存不足
+var ErrOutOfStock = errors.New("库存不足")
+
+// PurchaseMeta 兑换奖励的流水信息
+func PurchaseMeta(reward *models.Reward, description string) LogMeta {
+	return LogMeta{Description: description, RefType: "reward", RefID: reward.ID}
+}
+
+// BuyReward 扣减库存并按奖励计价币种扣除 price，返回扣款后的余额和扣款流水ID (实付为0时不产生流水, ID为0)
+func BuyReward(tx *gorm.DB, userID uint, reward *models.Reward, price int, meta LogMeta) (int, uint, error) {
+	// 有限库存原子扣减, 防止并发超卖
+	if reward.Stock >= 0 {
+		result := tx.Model(&models.Reward{}).
+			Where("id = ? AND stock > 0", reward.ID).
+			Update("stock", gorm.Expr("stock - 1"))
+		if result.Error != nil {
+			return 0, 0, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return 0, 0, ErrOutOfStock
+		}
+	}
+
+	balance, log, err := changeCurrency(tx, userID, reward.Currency, -price, meta)
+	if err != nil {
+		return balance, 0, err
+	}
+	var logID uint
+	if log != nil {
+		logID = log.ID
+	}
+	if err := FireWebhook(tx, userID, WebhookRewardPurchased, map[string]interface{}{
+		"rewardId": reward.ID,
+		"title":    reward.Title,
+		"currency": reward.Currency,
+		"price":    price,
+		"balance":  balance,
+	}); err != nil {
+		return balance, logID, err
+	}
+
+	// 大额兑换发布动态
+	if title := purchaseFeedTitle(reward, price); title != "" {
+		if err := PublishFeed(tx, userID, FeedPurchase, title, meta.RefType, reward.ID); err != nil {
+			return balance, logID, err
+		}
+	}
+	return balance, logID, nil
+}
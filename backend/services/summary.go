@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// weeklySummaryHour 每周一发送周报的时间 (默认时区)
+const weeklySummaryHour = 9
+
+// weekStartOf 所在周的周一零点
+func weekStartOf(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// SendWeeklySummaries 每周一按默认时区向开启周报的用户发送上周总结, 每人每周一封
+func SendWeeklySummaries(db *gorm.DB, now time.Time) error {
+	if !MailEnabled() {
+		return nil
+	}
+	loc, err := loadLocation(config.AppConfig.Reminder.DefaultTimezone)
+	if err != nil {
+		loc = time.Local
+	}
+	weekStart := weekStartOf(now.In(loc))
+	if now.Before(weekStart.Add(weeklySummaryHour * time.Hour)) {
+		return nil
+	}
+	lastWeek := weekStart.AddDate(0, 0, -7)
+
+	var sent []uint
+	db.Model(&models.EmailMessage{}).
+		Where("template = ? AND created_at >= ?", MailTemplateWeeklySummary, weekStart).
+		Pluck("user_id", &sent)
+	query := db.Where("status = 1 AND weekly_summary = ? AND email <> ''", true)
+	if len(sent) > 0 {
+		query = query.Where("id NOT IN ?", sent)
+	}
+	var users []models.SysUser
+	if err := query.Find(&users).Error; err != nil {
+		return err
+	}
+
+	for i := range users {
+		user := &users[i]
+		var tasks int64
+		db.Model(&models.UserTask{}).
+			Where("user_id = ? AND status = ? AND completed_at >= ? AND completed_at < ?", user.ID, TaskApproved, lastWeek, weekStart).
+			Count(&tasks)
+
+		var earned []struct {
+			Currency string
+			Total    int
+		}
+		db.Model(&models.UserLog{}).Select("currency, SUM(amount) AS total").
+			Where("user_id = ? AND type IN ? AND created_at >= ? AND created_at < ?",
+				user.ID, []string{"exp_in", "gold_in"}, lastWeek, weekStart).
+			Group("currency").Scan(&earned)
+		totals := map[string]int{}
+		for _, e := range earned {
+			totals[e.Currency] = e.Total
+		}
+
+		if err := EnqueueMail(db, user, MailTemplateWeeklySummary, MailData{
+			"Start": lastWeek.Format("01-02"),
+			"End":   weekStart.AddDate(0, 0, -1).Format("01-02"),
+			"Tasks": tasks,
+			"Exp":   totals["exp"],
+			"Gold":  totals["gold"],
+			"Level": user.Level,
+		}); err != nil {
+			return fmt.Errorf("用户 %d 周报发送失败: %w", user.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// RefTypeTask 任务奖励流水的关联类型
+const RefTypeTask = "task"
+
+// 收益溢出处理方式
+const (
+	OverflowDiscard = "discard"
+	OverflowConvert = "convert"
+)
+
+// TaskPayout 任务奖励发放结果
+type TaskPayout struct {
+	UserTask        models.UserTask   `json:"-"`
+	GoldReward      int               `json:"goldReward"` // 实际发放
+	ExpReward       int               `json:"expReward"`  // 实际发放
+	CurrencyRewards map[string]int    `json:"currencyRewards"`
+	GoldOverflow    int               `json:"goldOverflow"` // 超出每日上限的部分
+	ExpOverflow     int               `json:"expOverflow"`
+	Converted       map[string]int    `json:"converted"` // 溢出转换获得的币种
+	NewGold         int               `json:"newGold"`
+	SeasonPoints    int               `json:"seasonPoints"` // 获得的赛季积分
+	GuildWeek       *models.GuildWeek `json:"guildWeek"`    // 公会本周目标进度, 未加入公会时为空
+	BossDamage      int               `json:"bossDamage"`   // 对参与的首领造成的伤害
+	Pending         bool              `json:"pending"`      // 等待监护人审核, 奖励尚未发放
+	Exp             *ExpResult        `json:"-"`
+}
+
+// CompleteTask 记录任务完成并发放奖励, 需审核的家庭任务仅提交记录, 审核通过后再发放
+// 家庭任务由监护人发布和审核, 监护人自己不能完成
+func CompleteTask(tx *gorm.DB, userID uint, task *models.Task) (*TaskPayout, error) {
+	if task.HouseholdID != 0 {
+		if member, err := HouseholdOf(tx, userID); err == nil &&
+			member.HouseholdID == task.HouseholdID && member.Role == HouseholdGuardian {
+			return nil, ErrHouseholdSelfTask
+		}
+	}
+	payout := &TaskPayout{
+		UserTask: models.UserTask{
+			UserID:      userID,
+			TaskID:      task.ID,
+			Status:      TaskApproved,
+			CompletedAt: time.Now(),
+		},
+		CurrencyRewards: map[string]int{},
+		Converted:       map[string]int{},
+	}
+	if task.HouseholdID != 0 && task.RequireApproval {
+		payout.UserTask.Status = TaskPending
+		payout.Pending = true
+	}
+	if err := tx.Create(&payout.UserTask).Error; err != nil {
+		return nil, err
+	}
+	if payout.Pending {
+		return payout, nil
+	}
+	return payout, PayTaskReward(tx, userID, task, payout)
+}
+
+// PayTaskReward 按每日收益上限发放任务奖励，并处理溢出、赛季积分、首领伤害、公会周目标、动态与心愿自动储蓄
+func PayTaskReward(tx *gorm.DB, userID uint, task *models.Task, payout *TaskPayout) error {
+	meta := LogMeta{
+		Description: "完成任务: " + task.Title,
+		RefType:     RefTypeTask,
+		RefID:       task.ID,
+	}
+
+	// 锁定用户, 保证同一用户的上限统计串行
+	if _, err := LockUser(tx, userID); err != nil {
+		return err
+	}
+	payout.GoldReward, payout.ExpReward = task.GoldReward, task.ExpReward
+	caps := applicableCaps(tx, task.Category)
+	for _, limit := range caps {
+		gold, exp := earnedToday(tx, userID, limit.Category)
+		goldOver := overflow(payout.GoldReward, gold, limit.MaxGold)
+		expOver := overflow(payout.ExpReward, exp, limit.MaxExp)
+		if goldOver == 0 && expOver == 0 {
+			continue
+		}
+		payout.GoldReward -= goldOver
+		payout.ExpReward -= expOver
+		payout.GoldOverflow += goldOver
+		payout.ExpOverflow += expOver
+		if limit.Overflow == OverflowConvert && limit.ConvertCurrency != "" {
+			payout.Converted[limit.ConvertCurrency] += (goldOver + expOver) * limit.ConvertPercent / 100
+		}
+	}
+
+	newGold, err := ChangeGold(tx, userID, payout.GoldReward, meta)
+	if err != nil {
+		return err
+	}
+	payout.NewGold = newGold
+	if payout.Exp, err = ChangeExp(tx, userID, payout.ExpReward, meta); err != nil {
+		return err
+	}
+
+	// 额外币种奖励
+	for _, reward := range task.CurrencyRewards {
+		if _, err := ChangeCurrency(tx, userID, reward.Currency, reward.Amount, meta); err != nil {
+			return err
+		}
+		payout.CurrencyRewards[reward.Currency] += reward.Amount
+	}
+
+	// 溢出转换
+	for currency, amount := range payout.Converted {
+		if amount <= 0 {
+			continue
+		}
+		convertMeta := meta
+		convertMeta.Description = fmt.Sprintf("完成任务: %s (超出每日上限部分转换)", task.Title)
+		balance, err := ChangeCurrency(tx, userID, currency, amount, convertMeta)
+		if err != nil {
+			return err
+		}
+		if currency == CurrencyGold {
+			payout.NewGold = balance
+		}
+	}
+
+	// 赛季积分, 按实际发放的经验计算
+	if payout.SeasonPoints, err = AddSeasonPoints(tx, userID, payout.ExpReward); err != nil {
+		return err
+	}
+
+	// 首领讨伐, 按实际发放的经验造成伤害
+	if payout.BossDamage, err = AttackBosses(tx, userID, task, payout.ExpReward); err != nil {
+		return err
+	}
+
+	// 公会周目标进度
+	if payout.GuildWeek, err = AddGuildProgress(tx, userID); err != nil {
+		return err
+	}
+
+	// 发布动态
+	if err := publishTaskFeed(tx, userID, task, payout); err != nil {
+		return err
+	}
+
+	// 按心愿设置自动储蓄
+	if err := AutoSave(tx, userID, CurrencyGold, payout.GoldReward); err != nil {
+		return err
+	}
+	for currency, amount := range payout.CurrencyRewards {
+		if err := AutoSave(tx, userID, currency, amount); err != nil {
+			return err
+		}
+	}
+
+	return FireWebhook(tx, userID, WebhookTaskCompleted, map[string]interface{}{
+		"userTaskId":      payout.UserTask.ID,
+		"taskId":          task.ID,
+		"title":           task.Title,
+		"type":            task.Type,
+		"category":        task.Category,
+		"goldReward":      payout.GoldReward,
+		"expReward":       payout.ExpReward,
+		"currencyRewards": payout.CurrencyRewards,
+	})
+}
+
+// applicableCaps 适用于该分类任务的上限: 全局上限和分类上限
+func applicableCaps(db *gorm.DB, category string) []models.EarningCap {
+	var caps []models.EarningCap
+	db.Where("is_active = ? AND (category = ? OR category = ?)", true, "", category).
+		Order("category").
+		Find(&caps)
+	return caps
+}
+
+// earnedToday 统计用户今日通过任务获得的金币和经验, category 为空表示全部分类
+func earnedToday(db *gorm.DB, userID uint, category string) (gold, exp int) {
+	var stat struct {
+		Gold int
+		Exp  int
+	}
+	query := db.Model(&models.UserLog{}).
+		Where("user_log.user_id = ? AND user_log.ref_type = ? AND DATE(user_log.created_at) = ?",
+			userID, RefTypeTask, time.Now().Format("2006-01-02"))
+	if category != "" {
+		query = query.Joins("JOIN task ON task.id = user_log.ref_id").Where("task.category = ?", category)
+	}
+	query.Select("COALESCE(SUM(CASE WHEN user_log.type = ? THEN user_log.amount ELSE 0 END), 0) AS gold, "+
+		"COALESCE(SUM(CASE WHEN user_log.type = ? THEN user_log.amount ELSE 0 END), 0) AS exp", LogGoldIn, LogExpIn).
+		Scan(&stat)
+	return stat.Gold, stat.Exp
+}
+
+// overflow 计算本次收益超出上限的部分
+func overflow(reward, earned, max int) int {
+	if max <= 0 || reward <= 0 {
+		return 0
+	}
+	allowed := max - earned
+	if allowed < 0 {
+		allowed = 0
+	}
+	if reward > allowed {
+		return reward - allowed
+	}
+	return 0
+}
+
+// TaskStreak 连续完成任务的天数, 今天尚未完成时从昨天开始计算
+func TaskStreak(db *gorm.DB, userID uint) int {
+	var days []string
+	db.Raw("SELECT DISTINCT DATE_FORMAT(completed_at, '%Y-%m-%d') AS day FROM user_task "+
+		"WHERE user_id = ? AND status = ? ORDER BY day DESC LIMIT 366", userID, TaskApproved).Scan(&days)
+
+	streak := 0
+	day := time.Now()
+	for i, d := range days {
+		if i == 0 && d != day.Format("2006-01-02") {
+			day = day.AddDate(0, 0, -1)
+		}
+		if d != day.Format("2006-01-02") {
+			break
+		}
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
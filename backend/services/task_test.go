@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		name                string
+		reward, earned, max int
+		want                int
+	}{
+		{"未设置上限", 50, 1000, 0, 0},
+		{"奖励为0", 0, 90, 100, 0},
+		{"负数奖励不计溢出", -10, 90, 100, 0},
+		{"未超出上限", 30, 50, 100, 0},
+		{"恰好达到上限", 50, 50, 100, 0},
+		{"部分超出", 30, 90, 100, 20},
+		{"已达上限全部溢出", 30, 100, 100, 30},
+		{"此前已超出上限", 30, 120, 100, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overflow(tt.reward, tt.earned, tt.max); got != tt.want {
+				t.Errorf("overflow(%d, %d, %d) = %d, want %d", tt.reward, tt.earned, tt.max, got, tt.want)
+			}
+		})
+	}
+}
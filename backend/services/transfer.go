@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// 转账流水关联类型
+const (
+	RefTypeTransfer = "transfer"
+	RefTypeGift     = "gift"
+)
+
+// 转账类型
+const (
+	TransferTypeGold = "gold"
+	TransferTypeGift = "gift"
+)
+
+// 转账错误
+var (
+	ErrTransferSelf         = errors.New("不能转给自己")
+	ErrTransferReceiver     = errors.New("收款用户不存在或已被禁用")
+	ErrTransferSenderLocked = errors.New("账号已被禁用")
+	ErrTransferLevel        = errors.New("等级不足, 暂不能转账赠礼")
+	ErrTransferAccountAge   = errors.New("注册时间过短, 暂不能转账赠礼")
+	ErrTransferAmount       = errors.New("转账金额超出单笔限制")
+	ErrTransferDailyLimit   = errors.New("已超过今日转出额度")
+	ErrTransferDailyCount   = errors.New("已超过今日转出次数")
+	ErrTransferReceiveLimit = errors.New("对方今日收款已达上限")
+	ErrGiftRewardHidden     = errors.New("对方无法兑换该奖励")
+)
+
+// TransferUsage 今日转账额度使用情况
+type TransferUsage struct {
+	Sent       int `json:"sent"`
+	SentCount  int `json:"sentCount"`
+	DailyLimit int `json:"dailyLimit"`
+	DailyCount int `json:"dailyCount"`
+	MaxPer     int `json:"maxPerTransfer"`
+}
+
+// GetTransferUsage 统计用户今日转出金额与次数 (赠礼按金币计价的部分计入额度)
+func GetTransferUsage(db *gorm.DB, userID uint) TransferUsage {
+	cfg := config.AppConfig.Transfer
+	today := time.Now().Format("2006-01-02")
+
+	var stat struct {
+		Sent  int
+		Count int
+	}
+	db.Model(&models.Transfer{}).
+		Where("from_user_id = ? AND DATE(created_at) = ?", userID, today).
+		Select("COALESCE(SUM(CASE WHEN currency = ? THEN amount ELSE 0 END), 0) AS sent, COUNT(*) AS count", CurrencyGold).
+		Scan(&stat)
+
+	return TransferUsage{
+		Sent:       stat.Sent,
+		SentCount:  stat.Count,
+		DailyLimit: cfg.DailyLimit,
+		DailyCount: cfg.DailyCount,
+		MaxPer:     cfg.MaxPerTransfer,
+	}
+}
+
+// checkTransfer 风控校验，需在锁定双方用户后调用
+func checkTransfer(tx *gorm.DB, from, to *models.SysUser, currency string, amount int) error {
+	cfg := config.AppConfig.Transfer
+
+	if from.ID == to.ID {
+		return ErrTransferSelf
+	}
+	if from.Status != 1 {
+		return ErrTransferSenderLocked
+	}
+	if to.Status != 1 || to.TenantID != from.TenantID {
+		return ErrTransferReceiver
+	}
+	if from.Level < cfg.MinLevel {
+		return ErrTransferLevel
+	}
+	if time.Since(from.CreatedAt) < time.Duration(cfg.MinAccountDays)*24*time.Hour {
+		return ErrTransferAccountAge
+	}
+
+	usage := GetTransferUsage(tx, from.ID)
+	if cfg.DailyCount > 0 && usage.SentCount >= cfg.DailyCount {
+		return ErrTransferDailyCount
+	}
+	if currency != CurrencyGold {
+		return nil
+	}
+	if cfg.MaxPerTransfer > 0 && amount > cfg.MaxPerTransfer {
+		return ErrTransferAmount
+	}
+	if cfg.DailyLimit > 0 && usage.Sent+amount > cfg.DailyLimit {
+		return ErrTransferDailyLimit
+	}
+
+	if cfg.DailyReceiveLimit > 0 {
+		var received int
+		tx.Model(&models.Transfer{}).
+			Where("to_user_id = ? AND currency = ? AND DATE(created_at) = ?", to.ID, CurrencyGold, time.Now().Format("2006-01-02")).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&received)
+		if received+amount > cfg.DailyReceiveLimit {
+			return ErrTransferReceiveLimit
+		}
+	}
+	return nil
+}
+
+// lockPair 按ID顺序锁定双方用户，避免互相转账时死锁
+func lockPair(tx *gorm.DB, fromID, toID uint) (*models.SysUser, *models.SysUser, error) {
+	firstID, secondID := fromID, toID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+	first, err := LockUser(tx, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second := first
+	if secondID != firstID {
+		if second, err = LockUser(tx, secondID); err != nil {
+			return nil, nil, ErrTransferReceiver
+		}
+	}
+	if first.ID == fromID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+// SendGold 金币转账，双方流水共享同一条转账记录ID
+func SendGold(tx *gorm.DB, fromID, toID uint, amount int, remark string) (*models.Transfer, error) {
+	from, to, err := lockPair(tx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransfer(tx, from, to, CurrencyGold, amount); err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		FromUserID: from.ID,
+		ToUserID:   to.ID,
+		Type:       TransferTypeGold,
+		Currency:   CurrencyGold,
+		Amount:     amount,
+		Remark:     remark,
+	}
+	if err := tx.Create(transfer).Error; err != nil {
+		return nil, err
+	}
+
+	if _, err := ChangeGold(tx, from.ID, -amount, LogMeta{
+		Description: fmt.Sprintf("转账给 %s", displayName(to)),
+		RefType:     RefTypeTransfer,
+		RefID:       transfer.ID,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := ChangeGold(tx, to.ID, amount, LogMeta{
+		Description: fmt.Sprintf("收到 %s 的转账", displayName(from)),
+		RefType:     RefTypeTransfer,
+		RefID:       transfer.ID,
+	}); err != nil {
+		return nil, err
+	}
+	return transfer, Notify(tx, &models.Notification{
+		UserID:   to.ID,
+		Type:     NotifyTransfer,
+		Title:    "收到转账",
+		Body:     transferNotice(fmt.Sprintf("%s 向你转账 %d 金币", displayName(from), amount), remark),
+		Link:     "/transfers",
+		SenderID: from.ID,
+		RefType:  RefTypeTransfer,
+		RefID:    transfer.ID,
+	})
+}
+
+// GiftReward 为他人兑换奖励，由赠送方按兑换价格付款，礼物记录在转账记录中
+// 收礼方获得一件背包物品, 与赠送方流水共享同一条转账记录ID
+// 家庭奖励只能在同一家庭的成员之间赠送
+func GiftReward(tx *gorm.DB, fromID, toID uint, reward *models.Reward, remark string) (*models.Transfer, error) {
+	from, to, err := lockPair(tx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	if reward.HouseholdID != 0 {
+		for _, id := range []uint{from.ID, to.ID} {
+			if member, err := HouseholdOf(tx, id); err != nil || member.HouseholdID != reward.HouseholdID {
+				return nil, ErrGiftRewardHidden
+			}
+		}
+	}
+	breakdown, _, err := QuotePrice(tx, from.ID, reward, "")
+	if err != nil {
+		return nil, err
+	}
+	price := breakdown.Final
+	if err := checkTransfer(tx, from, to, reward.Currency, price); err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		FromUserID: from.ID,
+		ToUserID:   to.ID,
+		Type:       TransferTypeGift,
+		Currency:   reward.Currency,
+		Amount:     price,
+		RewardID:   reward.ID,
+		Remark:     remark,
+	}
+	if err := tx.Create(transfer).Error; err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("赠送 %s: %s", displayName(to), reward.Title)
+	if detail := breakdown.Describe(); detail != "" {
+		description += " (" + detail + ")"
+	}
+	if _, _, err := BuyReward(tx, from.ID, reward, price, LogMeta{
+		Description: description,
+		RefType:     RefTypeGift,
+		RefID:       transfer.ID,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.UserItem{
+		UserID:   to.ID,
+		Kind:     DropReward,
+		Name:     reward.Title,
+		RewardID: reward.ID,
+		Source:   RefTypeGift,
+		SourceID: transfer.ID,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return transfer, Notify(tx, &models.Notification{
+		UserID:   to.ID,
+		Type:     NotifyGift,
+		Title:    "收到礼物",
+		Body:     transferNotice(fmt.Sprintf("%s 送给你: %s", displayName(from), reward.Title), remark),
+		Link:     "/transfers",
+		SenderID: from.ID,
+		RefType:  RefTypeGift,
+		RefID:    transfer.ID,
+	})
+}
+
+// transferNotice 转账/礼物通知内容, 附带留言
+func transferNotice(text, remark string) string {
+	if remark == "" {
+		return text
+	}
+	return fmt.Sprintf("%s, 留言: %s", text, remark)
+}
+
+// displayName 用户展示名
+func displayName(user *models.SysUser) string {
+	if user.Nickname != "" {
+		return user.Nickname
+	}
+	return user.Username
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// emailVerifyTTL 验证邮箱链接有效期
+const emailVerifyTTL = 24 * time.Hour
+
+// ErrVerifyToken 验证令牌无效
+var ErrVerifyToken = errors.New("验证链接无效或已过期")
+
+// RequestEmailChange 向新邮箱发送验证邮件, 验证通过后才替换账号邮箱
+func RequestEmailChange(tx *gorm.DB, user *models.SysUser, email string) error {
+	token, err := randomHex(32)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&models.EmailVerification{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(emailVerifyTTL),
+	}).Error; err != nil {
+		return err
+	}
+
+	name := user.Nickname
+	if name == "" {
+		name = user.Username
+	}
+	return enqueueMail(tx, user.TenantID, user.ID, email, MailTemplateVerifyEmail, MailData{
+		"Name":    name,
+		"URL":     config.AppConfig.Mail.VerifyURL + "?token=" + token,
+		"Minutes": int(emailVerifyTTL / time.Minute),
+	})
+}
+
+// VerifyEmail 校验令牌并将账号邮箱改为待验证的邮箱, 同时作废该用户其他未使用的令牌
+func VerifyEmail(tx *gorm.DB, token string) error {
+	var verification models.EmailVerification
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).
+		First(&verification).Error; err != nil {
+		return ErrVerifyToken
+	}
+
+	var user models.SysUser
+	if err := tx.Where("status = 1").First(&user, verification.UserID).Error; err != nil {
+		return ErrVerifyToken
+	}
+	if err := tx.Model(&user).Update("email", verification.Email).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.EmailVerification{}).
+		Where("user_id = ? AND used_at IS NULL", verification.UserID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"life-rpg/config"
+	"life-rpg/models"
+
+	"gorm.io/gorm"
+)
+
+// Webhook事件
+const (
+	WebhookTaskCompleted   = "task.completed"
+	WebhookRewardPurchased = "reward.purchased"
+	WebhookLevelUp         = "user.level_up"
+	WebhookNotification    = "notification" // 选择了 webhook 渠道的通知, 如任务提醒
+	WebhookPing            = "ping"         // 测试投递
+)
+
+// WebhookEvents 可订阅的事件
+var WebhookEvents = []string{WebhookTaskCompleted, WebhookRewardPurchased, WebhookLevelUp, WebhookNotification}
+
+// init 注册 Webhook 通知渠道, 无需额外配置
+func init() {
+	RegisterChannel(webhookChannel{})
+}
+
+// 投递状态
+const (
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed"
+)
+
+// webhookBatchSize 每次处理的投递数
+const webhookBatchSize = 50
+
+// 请求头, 签名为 HMAC-SHA256(secret, 时间戳 + "." + 请求体)
+const (
+	HeaderWebhookEvent     = "X-LifeRPG-Event"
+	HeaderWebhookDelivery  = "X-LifeRPG-Delivery"
+	HeaderWebhookTimestamp = "X-LifeRPG-Timestamp"
+	HeaderWebhookSignature = "X-LifeRPG-Signature"
+)
+
+// Webhook错误
+var (
+	ErrWebhookNotFound = errors.New("订阅不存在")
+	ErrWebhookURL      = errors.New("地址应为 http 或 https 链接")
+	ErrWebhookEvents   = errors.New("请选择有效的订阅事件")
+	ErrWebhookLimit    = errors.New("订阅数量已达上限")
+	ErrWebhookPrivate  = errors.New("不允许投递到内网地址")
+)
+
+// WebhookPayload 推送的请求体
+type WebhookPayload struct {
+	ID        string      `json:"id"` // 事件ID, 重试时不变, 可用于去重
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	User      webhookUser `json:"user"`
+	Data      interface{} `json:"data"`
+}
+
+// webhookUser 事件所属用户
+type webhookUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+}
+
+// NormalizeWebhook 校验订阅地址和事件, 未设置密钥时生成
+func NormalizeWebhook(hook *models.Webhook) error {
+	u, err := url.Parse(strings.TrimSpace(hook.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(hook.URL) > 500 {
+		return ErrWebhookURL
+	}
+	hook.URL = u.String()
+
+	seen := map[string]bool{}
+	var events []string
+	for _, event := range strings.Split(hook.Events, ",") {
+		event = strings.TrimSpace(event)
+		if event == "" || seen[event] {
+			continue
+		}
+		if !containsString(WebhookEvents, event) {
+			return ErrWebhookEvents
+		}
+		seen[event] = true
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return ErrWebhookEvents
+	}
+	hook.Events = strings.Join(events, ",")
+
+	if hook.Secret == "" {
+		if hook.Secret, err = randomHex(24); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsString 切片中是否包含字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckWebhookLimit 校验订阅数量, userID 为0时统计空间级订阅
+func CheckWebhookLimit(db *gorm.DB, userID uint) error {
+	var count int64
+	db.Model(&models.Webhook{}).Where("user_id = ?", userID).Count(&count)
+	if limit := config.AppConfig.Webhook.MaxPerOwner; limit > 0 && int(count) >= limit {
+		return ErrWebhookLimit
+	}
+	return nil
+}
+
+// DeleteWebhook 删除订阅及其投递记录
+func DeleteWebhook(tx *gorm.DB, hook *models.Webhook) error {
+	if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(hook).Error
+}
+
+// FireWebhook 为订阅了该事件的用户订阅和空间级订阅写入投递记录, 随业务事务提交后由后台任务推送
+func FireWebhook(tx *gorm.DB, userID uint, event string, data interface{}) error {
+	var user models.SysUser
+	if err := tx.Select("id", "username", "nickname", "tenant_id").First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var hooks []models.Webhook
+	if err := tx.Where("is_active = ? AND tenant_id = ? AND user_id IN ?", true, user.TenantID, []uint{0, userID}).
+		Find(&hooks).Error; err != nil {
+		return err
+	}
+	var matched []models.Webhook
+	for _, hook := range hooks {
+		if containsString(strings.Split(hook.Events, ","), event) {
+			matched = append(matched, hook)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	payload, err := buildWebhookPayload(&user, event, data)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]models.WebhookDelivery, 0, len(matched))
+	for _, hook := range matched {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// buildWebhookPayload 序列化推送内容
+func buildWebhookPayload(user *models.SysUser, event string, data interface{}) (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(WebhookPayload{
+		ID:        id,
+		Event:     event,
+		CreatedAt: time.Now(),
+		User:      webhookUser{ID: user.ID, Username: user.Username, Nickname: user.Nickname},
+		Data:      data,
+	})
+	return string(body), err
+}
+
+// SendTestWebhook 立即向订阅地址推送一次测试事件, 不进入重试队列
+func SendTestWebhook(db *gorm.DB, hook *models.Webhook, user *models.SysUser) (*models.WebhookDelivery, error) {
+	payload, err := buildWebhookPayload(user, WebhookPing, map[string]interface{}{
+		"webhookId": hook.ID,
+		"message":   "这是一条测试推送",
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := &models.WebhookDelivery{
+		WebhookID:     hook.ID,
+		Event:         WebhookPing,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		Attempts:      1,
+		NextAttemptAt: time.Now(),
+	}
+	if err := db.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+
+	updates := deliverWebhook(hook, delivery)
+	if updates["status"] == nil {
+		updates["status"] = DeliveryFailed
+	}
+	if err := db.Model(delivery).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return delivery, db.First(delivery, delivery.ID).Error
+}
+
+// webhookBackoff 第n次尝试失败后的重试间隔: 30秒起按2倍递增, 最长1小时
+func webhookBackoff(attempts int) time.Duration {
+	wait := 30 * time.Second
+	for i := 1; i < attempts && wait < time.Hour; i++ {
+		wait *= 2
+	}
+	if wait > time.Hour {
+		wait = time.Hour
+	}
+	return wait
+}
+
+// ProcessWebhookDeliveries 推送到期的投递, 失败时按退避间隔重试, 超过最大次数标记为失败
+func ProcessWebhookDeliveries(db *gorm.DB, now time.Time) error {
+	var deliveries []models.WebhookDelivery
+	if err := db.Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("id").Limit(webhookBatchSize).Find(&deliveries).Error; err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		// 先记录本次尝试并推迟下次尝试时间, 多实例同时处理时只有一个能占用
+		attempts := delivery.Attempts + 1
+		result := db.Model(delivery).Where("status = ? AND attempts = ?", DeliveryPending, delivery.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        attempts,
+				"next_attempt_at": now.Add(webhookBackoff(attempts)),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("投递 %d 更新失败: %w", delivery.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		var updates map[string]interface{}
+		if delivery.Webhook == nil || !delivery.Webhook.IsActive {
+			updates = map[string]interface{}{"status": DeliveryFailed, "last_error": "订阅已停用"}
+		} else {
+			updates = deliverWebhook(delivery.Webhook, delivery)
+			if updates["status"] == nil && attempts >= config.AppConfig.Webhook.MaxAttempts {
+				updates["status"] = DeliveryFailed
+			}
+		}
+		if err := db.Model(delivery).Updates(updates).Error; err != nil {
+			return fmt.Errorf("投递 %d 更新失败: %w", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// deliverWebhook 发送一次请求, 返回需要更新的投递字段; 成功时包含 status, 失败时不含
+func deliverWebhook(hook *models.Webhook, delivery *models.WebhookDelivery) map[string]interface{} {
+	updates := map[string]interface{}{}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		updates["last_error"] = truncateRunes(err.Error(), 500)
+		return updates
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LifeRPG-Webhook/1.0")
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, "sha256="+SignWebhook(hook.Secret, timestamp, delivery.Payload))
+
+	start := time.Now()
+	// 用户订阅可读取投递记录中的响应内容, 不允许访问内网地址
+	allowPrivate := config.AppConfig.Webhook.AllowPrivate && hook.UserID == 0
+	resp, err := webhookClient(allowPrivate).Do(req)
+	updates["duration"] = int(time.Since(start) / time.Millisecond)
+	if err != nil {
+		updates["last_error"] = truncateRunes(err.Error(), 500)
+		return updates
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 2000))
+	updates["response_code"] = resp.StatusCode
+	updates["response_body"] = truncateRunes(string(bytes.ToValidUTF8(body, nil)), 500)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		updates["last_error"] = fmt.Sprintf("响应状态码 %d", resp.StatusCode)
+		return updates
+	}
+	updates["status"] = DeliverySuccess
+	updates["last_error"] = ""
+	updates["delivered_at"] = time.Now()
+	return updates
+}
+
+// SignWebhook 计算签名, 接收方用同样方式计算并比对 X-LifeRPG-Signature
+func SignWebhook(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookClient 投递使用的HTTP客户端, 不跟随重定向; 未允许内网时在建立连接时校验解析后的地址
+func webhookClient(allowPrivate bool) *http.Client {
+	cfg := config.AppConfig.Webhook
+	dialer := &net.Dialer{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	if !allowPrivate {
+		dialer.Control = denyPrivateAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// denyPrivateAddress 拒绝连接回环、内网和链路本地地址
+func denyPrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return ErrWebhookPrivate
+	}
+	return nil
+}
+
+// truncateRunes 按字符截断
+func truncateRunes(s string, n int) string {
+	if len([]rune(s)) > n {
+		return string([]rune(s)[:n])
+	}
+	return s
+}
+
+// webhookChannel Webhook通知渠道, 推送给订阅了 notification 事件的订阅
+type webhookChannel struct{}
+
+// Name 渠道标识
+func (webhookChannel) Name() string {
+	return "webhook"
+}
+
+// Send 写入通知事件的投递记录
+func (webhookChannel) Send(db *gorm.DB, user *models.SysUser, n *models.Notification) error {
+	return FireWebhook(db, user.ID, WebhookNotification, n)
+}
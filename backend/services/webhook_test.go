@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		name, secret, timestamp, body string
+		want                          string
+	}{
+		// 期望值由独立实现的 HMAC-SHA256 计算
+		{"普通负载", "secret", "1700000000", `{"event":"ping"}`,
+			"4d39bd2442f073b6bc62e95d0297ce25475582a17389ab860abdc778fe1d9f77"},
+		{"空密钥和空负载", "", "0", "",
+			"b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignWebhook(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("SignWebhook() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	base := SignWebhook("secret", "1700000000", "body")
+	if SignWebhook("secret", "1700000001", "body") == base {
+		t.Error("时间戳变化时签名应不同")
+	}
+	if SignWebhook("other", "1700000000", "body") == base {
+		t.Error("密钥变化时签名应不同")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDenyPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.0.0.8:8080", true},
+		{"172.16.3.4:443", true},
+		{"192.168.1.10:80", true},
+		{"169.254.169.254:80", true},
+		{"0.0.0.0:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[fd00::1]:80", true},
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1::]:443", false},
+	}
+	for _, tt := range tests {
+		err := denyPrivateAddress("tcp", tt.address, nil)
+		if denied := err != nil; denied != tt.denied {
+			t.Errorf("denyPrivateAddress(%s) denied = %v, want %v", tt.address, denied, tt.denied)
+		}
+	}
+}